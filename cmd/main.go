@@ -13,7 +13,7 @@ import (
 )
 
 func main() {
-	use := flag.Bool("d", false, "use local storage")
+	use := flag.Bool("d", false, "use local storage, same as STORAGE_BACKEND=memory (deprecated)")
 	flag.Parse()
 
 	logger, err := zap.NewProduction()
@@ -24,13 +24,17 @@ func main() {
 
 	config, error := initialize.Load()
 	if error != nil {
-		logger.Error("Failed to initialize config", zap.Error(error))
+		logger.Fatal("Failed to initialize config", zap.Error(error))
+	}
+	if *use {
+		logger.Warn("-d is deprecated, set STORAGE_BACKEND=memory instead")
+		config.StorageBackend = "memory"
 	}
 
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
-	if err := app.Run(ctx, config, logger, *use); err != nil {
+	if err := app.Run(ctx, config, logger); err != nil {
 		logger.Error("Failed to run server", zap.Error(err))
 	}
 }
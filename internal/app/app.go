@@ -3,54 +3,217 @@ package app
 import (
 	"context"
 	"go.uber.org/zap"
+	"time"
 	"urlShortener/internal/controller"
 	"urlShortener/internal/initialize"
+	"urlShortener/internal/metrics"
 	"urlShortener/internal/repository"
 	http "urlShortener/internal/server_http"
 	"urlShortener/internal/service"
+	"urlShortener/internal/utils"
 )
 
-func Run(ctx context.Context, config *initialize.Config, logger *zap.Logger, use bool) error {
+func Run(ctx context.Context, config *initialize.Config, logger *zap.Logger) error {
 	var err error
-	var pgDb *initialize.DB
+	var db *initialize.DB
 	var shortenerRepository service.SwapRepository
+	var pgRepository *repository.ShortenerRepository
+	var clickRepository service.ClickRepository
+	var keyRepository service.KeyRepository
+	var rateLimiter http.RateLimiter
 
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	if !use {
-		pgDb, err = initialize.NewClient(ctx, config.PGMaxAttemption, config)
+	var appMetrics *metrics.Metrics
+	if config.MetricsEnabled {
+		appMetrics = metrics.New()
+	}
+
+	switch config.StorageBackend {
+	case "postgres":
+		db, err = initialize.NewClient(ctx, config.PGMaxAttemption, config)
 		if err != nil {
 			logger.Error("error initializing pgDB", zap.Error(err))
 			return err
 		}
-		shortenerRepository, err = repository.NewShortenerRepository(pgDb, logger)
+		pgRepository, err = repository.NewShortenerRepository(db, logger)
 
 		if err != nil {
 			logger.Error("error creating shortener repository", zap.Error(err))
 			return err
 		}
+		shortenerRepository = pgRepository
+		clickRepository = repository.NewClickRepository(db, logger)
+		keyRepository = repository.NewKeyRepository(db, logger)
+		if config.RateLimitBackend == "postgres" {
+			pgRateLimiter := repository.NewRateLimitRepository(db, logger)
+			go sweepRateLimits(ctx, pgRateLimiter, config, logger)
+			rateLimiter = pgRateLimiter
+		}
 		logger.Info("successfully connected to pgDB")
 
-	} else {
-		shortenerRepository = repository.NewURLStorage(logger)
-		logger.Info("initializing shortener repository with local database")
+	case "sqlite":
+		db, err = initialize.NewSQLiteClient(ctx, config.SQLitePath)
+		if err != nil {
+			logger.Error("error opening sqlite database", zap.String("path", config.SQLitePath), zap.Error(err))
+			return err
+		}
+		shortenerRepository, err = repository.NewSQLiteRepository(db, logger)
+		if err != nil {
+			logger.Error("error creating shortener repository", zap.Error(err))
+			db.Close()
+			return err
+		}
+		clickRepository = repository.NewClickStorage(config.ClickRingSize, logger)
+		keyRepository = repository.NewKeyStorage(logger)
+		logger.Info("initializing shortener repository with sqlite database", zap.String("path", config.SQLitePath))
+
+	default:
+		if config.StorageFile != "" {
+			fileStorage, err := repository.NewFileStorage(config.StorageFile, logger)
+			if err != nil {
+				logger.Error("error opening storage file", zap.String("path", config.StorageFile), zap.Error(err))
+				return err
+			}
+			defer fileStorage.Close()
+			fileStorage.SyncWrites(config.StorageFsync)
+			go fileStorage.RunCompaction(ctx, config.StorageCompactInterval)
+			shortenerRepository = fileStorage
+			logger.Info("initializing shortener repository with storage file", zap.String("path", config.StorageFile))
+		} else {
+			shortenerRepository = repository.NewURLStorage(logger)
+			logger.Info("initializing shortener repository with local database")
+		}
+		clickRepository = repository.NewClickStorage(config.ClickRingSize, logger)
+		keyRepository = repository.NewKeyStorage(logger)
+	}
+
+	if appMetrics != nil {
+		shortenerRepository = repository.NewInstrumentedRepository(shortenerRepository, appMetrics)
+		if db != nil && db.Pool != nil {
+			appMetrics.RegisterPool(db.Pool)
+		}
+	}
+
+	// The memory backend is a map already, caching only pays off in front of a database.
+	if config.StorageBackend != "memory" && config.CacheSize > 0 {
+		cache := repository.NewCachingRepository(shortenerRepository, repository.CacheConfig{
+			Size:        config.CacheSize,
+			TTL:         config.CacheTTL,
+			NegativeTTL: config.CacheNegativeTTL,
+		}, logger)
+		shortenerRepository = cache
+		logger.Info("caching short URL lookups", zap.Int("size", config.CacheSize), zap.Duration("ttl", config.CacheTTL))
+
+		// Replicas sharing the links table evict each other's writes from their caches.
+		if pgRepository != nil && config.CacheNotifyChannel != "" {
+			pgRepository.NotifyChanges(config.CacheNotifyChannel)
+			go repository.NewLinkListener(db, config.CacheNotifyChannel, cache, logger).Run(ctx)
+		}
+	}
+
+	if rateLimiter == nil {
+		if config.RateLimitBackend == "postgres" {
+			logger.Warn("RATE_LIMIT_BACKEND=postgres needs STORAGE_BACKEND=postgres, using memory")
+		}
+		rateLimiter = repository.NewRateLimitStorage()
 	}
 
 	//shortenerRepository, err := repository.NewShortenerRepository(pgDb.Pool)
 
+	generator, err := utils.NewCodeGenerator(config.CodeGenerator, config.CodeAlphabet)
+	if err != nil {
+		logger.Error("error creating code generator", zap.Error(err))
+		return err
+	}
+	if config.CodeSecret != "" {
+		generator, err = utils.NewFeistelGenerator(generator, config.CodeSecret, config.CodeSecretBits)
+		if err != nil {
+			logger.Error("error creating code obfuscation", zap.Error(err))
+			return err
+		}
+	}
+
+	var screeners service.ScreenerChain
+	var redirectScreener service.URLScreener
+	if config.BlocklistFile != "" {
+		blocklist, err := service.NewBlocklistScreener(config.BlocklistFile, logger)
+		if err != nil {
+			logger.Error("error loading blocklist", zap.Error(err))
+			return err
+		}
+		go blocklist.Watch(ctx, config.BlocklistReload)
+		screeners = append(screeners, blocklist)
+		redirectScreener = blocklist
+	}
+	if config.ReputationURL != "" {
+		screeners = append(screeners, service.NewReputationScreener(
+			config.ReputationURL, config.ReputationTimeout, config.ReputationFailClosed, logger))
+	}
+
 	shortenerService := service.NewShortenerService(service.Deps{
-		Repository: shortenerRepository,
-		Config:     config,
+		Repository:       shortenerRepository,
+		ClickRepository:  clickRepository,
+		Generator:        generator,
+		Screener:         screeners,
+		RedirectScreener: redirectScreener,
+		Config:           config,
+		Logger:           logger,
+	})
+
+	go shortenerService.SweepExpired(ctx, config.SweepInterval)
+	go shortenerService.RunClickRecorder(ctx)
+
+	keyService := service.NewKeyService(service.KeyDeps{
+		Repository: keyRepository,
 		Logger:     logger,
 	})
 
+	// Keys from config are imported on every start. Postgres keeps them between
+	// runs, so a key already in api_keys is left as it is.
+	for _, entry := range config.APIKeys {
+		name, rawKey := service.ConfigKey(entry)
+		if _, err := keyService.ImportKey(ctx, name, rawKey); err != nil {
+			return err
+		}
+	}
+
 	shortenerController := controller.NewShortenerController(shortenerService, logger)
+	shortenerController.SetBatchMaxItems(config.BatchMaxItems)
+	keyController := controller.NewKeyController(keyService, logger)
 
-	server := http.NewServer(http.ServerConfig{
-		Controllers: []http.Controller{shortenerController},
-		Logger:      logger,
-	})
+	trustedProxies, err := config.TrustedProxyPrefixes()
+	if err != nil {
+		return err
+	}
+
+	serverConfig := http.ServerConfig{
+		Controllers:      []http.Controller{shortenerController},
+		AdminControllers: []http.Controller{keyController},
+		AdminToken:       config.AdminToken,
+		RateLimiter:      rateLimiter,
+		RateLimits: http.RateLimits{
+			Create:   config.CreateRateLimit(),
+			Redirect: config.RedirectRateLimit(),
+			Auth:     config.AuthRateLimit(),
+		},
+		TrustedProxies: trustedProxies,
+		Logger:         logger,
+	}
+	if config.AuthRequired {
+		serverConfig.Auth = keyService
+	}
+	if appMetrics != nil {
+		serverConfig.Observer = appMetrics
+		go func() {
+			if err := appMetrics.Serve(ctx, config.MetricsHost+":"+config.MetricsPort, logger); err != nil {
+				cancel()
+			}
+		}()
+	}
+
+	server := http.NewServer(serverConfig)
 
 	go func() {
 		if err := server.Start(config.HTTPHost + ":" + config.HTTPPort); err != nil {
@@ -63,8 +226,8 @@ func Run(ctx context.Context, config *initialize.Config, logger *zap.Logger, use
 
 	<-ctx.Done()
 
-	if !use {
-		pgDb.Pool.Close()
+	if db != nil {
+		db.Close()
 	}
 
 	server.Shutdown()
@@ -74,3 +237,21 @@ func Run(ctx context.Context, config *initialize.Config, logger *zap.Logger, use
 	logger.Info("shortener service shortener shutdown")
 	return nil
 }
+
+// sweepRateLimits drops shared buckets idle long enough to have refilled.
+func sweepRateLimits(ctx context.Context, repo *repository.PgRateLimitRepository, config *initialize.Config, logger *zap.Logger) {
+	idle := max(config.CreateRateLimit().FillTime(), config.RedirectRateLimit().FillTime(), config.AuthRateLimit().FillTime())
+	ticker := time.NewTicker(config.SweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if _, err := repo.DeleteIdle(ctx, now.Add(-idle)); err != nil && ctx.Err() == nil {
+				logger.Error("error sweeping rate limits", zap.Error(err))
+			}
+		}
+	}
+}
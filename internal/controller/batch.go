@@ -0,0 +1,174 @@
+package controller
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+	"io"
+	"strings"
+	"urlShortener/internal/model"
+	httpserver "urlShortener/internal/server_http"
+	"urlShortener/internal/service"
+)
+
+const (
+	mimeNDJSON = "application/x-ndjson"
+
+	// ndjsonMaxLine bounds a single line of an NDJSON batch.
+	ndjsonMaxLine = 1 << 20
+)
+
+var errInvalidBatch = errors.New("invalid batch payload")
+
+// SetBatchMaxItems makes BatchShortenURLs stop reading a batch as soon as
+// it holds more than maxItems requests. Zero reads batches of any size.
+func (s *ShortenerController) SetBatchMaxItems(maxItems int) {
+	s.batchMaxItems = maxItems
+}
+
+// BatchShortenURLs shortens a JSON array of requests, or one request per
+// line with Content-Type application/x-ndjson. The body is buffered by the
+// server, so its size is bounded by Fiber's BodyLimit; decoding it item by
+// item only saves building requests past BATCH_MAX_ITEMS. Results come back
+// in the same order and format, each with its own status.
+func (s *ShortenerController) BatchShortenURLs(c fiber.Ctx) error {
+	ndjson := strings.HasPrefix(c.Get(fiber.HeaderContentType), mimeNDJSON)
+
+	var reqs []model.Request
+	var items []model.BatchItem
+	var err error
+	if ndjson {
+		reqs, items, err = decodeNDJSON(bytes.NewReader(c.Body()), s.batchMaxItems)
+	} else {
+		reqs, err = decodeJSONArray(bytes.NewReader(c.Body()), s.batchMaxItems)
+		items = make([]model.BatchItem, len(reqs))
+	}
+	if err != nil {
+		if errors.Is(err, service.ErrBatchTooLarge) {
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request payload"})
+	}
+
+	// Lines that failed to decode already have a result, the rest go to the service.
+	var valid []model.Request
+	var positions []int
+	for i, req := range reqs {
+		if items[i].Status != 0 {
+			continue
+		}
+		req.Owner = owner(c)
+		valid = append(valid, req)
+		positions = append(positions, i)
+	}
+
+	// Every item is charged to the create budget, an empty batch still costs a request.
+	if !httpserver.ChargeRate(c, max(len(valid), 1)) {
+		return httpserver.RateLimitExceeded(c)
+	}
+
+	results, err := s.shortenerService.CreateShortURLs(c.Context(), valid)
+	if err != nil {
+		if errors.Is(err, service.ErrBatchTooLarge) {
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{"error": err.Error()})
+		}
+		s.logger.Error("Failed to create short urls", zap.Int("count", len(valid)), zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	for i, result := range results {
+		items[positions[i]] = batchItem(result)
+	}
+
+	if !ndjson {
+		return c.Status(fiber.StatusOK).JSON(items)
+	}
+
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, item := range items {
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+	}
+	c.Set(fiber.HeaderContentType, mimeNDJSON)
+	return c.Status(fiber.StatusOK).Send(body.Bytes())
+}
+
+func batchTooLarge(maxItems int) error {
+	return fmt.Errorf("%w: at most %d items are allowed", service.ErrBatchTooLarge, maxItems)
+}
+
+// decodeJSONArray reads the requests of a JSON array one at a time and
+// gives up once there are more than maxItems of them.
+func decodeJSONArray(r io.Reader, maxItems int) ([]model.Request, error) {
+	decoder := json.NewDecoder(r)
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return nil, errInvalidBatch
+	}
+
+	var reqs []model.Request
+	for decoder.More() {
+		if maxItems > 0 && len(reqs) == maxItems {
+			return nil, batchTooLarge(maxItems)
+		}
+		var req model.Request
+		if err := decoder.Decode(&req); err != nil {
+			return nil, err
+		}
+		reqs = append(reqs, req)
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errInvalidBatch
+	}
+	return reqs, nil
+}
+
+// decodeNDJSON reads one request per non-empty line and gives up once there
+// are more than maxItems of them. A line that is not a valid request gets
+// its failed result right away.
+func decodeNDJSON(r io.Reader, maxItems int) ([]model.Request, []model.BatchItem, error) {
+	var reqs []model.Request
+	var items []model.BatchItem
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), ndjsonMaxLine)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if maxItems > 0 && len(reqs) == maxItems {
+			return nil, nil, batchTooLarge(maxItems)
+		}
+		var req model.Request
+		var item model.BatchItem
+		if err := json.Unmarshal(line, &req); err != nil {
+			item = model.BatchItem{Status: fiber.StatusBadRequest, Error: "Invalid request payload"}
+		}
+		reqs = append(reqs, req)
+		items = append(items, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return reqs, items, nil
+}
+
+func batchItem(result service.BatchResult) model.BatchItem {
+	if result.Err != nil {
+		status, reason := createStatus(result.Err)
+		return model.BatchItem{Status: status, Error: result.Err.Error(), Reason: reason}
+	}
+	return model.BatchItem{
+		Status:    fiber.StatusOK,
+		URL:       result.Response.URL,
+		ExpiresAt: result.Response.ExpiresAt,
+	}
+}
@@ -0,0 +1,120 @@
+package controller_test
+
+import (
+	"bytes"
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"urlShortener/internal/controller"
+	"urlShortener/internal/model"
+	"urlShortener/internal/service"
+	mockService "urlShortener/mocks"
+)
+
+func TestBatchShortenURLs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockShortenerService := mockService.NewMockShortenerServiceInterface(ctrl)
+
+	app := fiber.New()
+	shortenerController := controller.NewShortenerController(mockShortenerService, zap.NewNop())
+	app.Post("/api/batch", shortenerController.BatchShortenURLs)
+
+	// Тест: Массив JSON, результаты в порядке запросов
+	t.Run("json array", func(t *testing.T) {
+		mockShortenerService.EXPECT().
+			CreateShortURLs(gomock.Any(), []model.Request{{URL: "https://example.com"}, {URL: "ftp://example.com"}}).
+			Return([]service.BatchResult{
+				{Response: &model.Response{URL: "http://short.url/b"}},
+				{Err: &service.URLError{Reason: service.ReasonSchemeNotAllowed, Message: `scheme "ftp" is not allowed`}},
+			}, nil)
+
+		reqBody := `[{"url":"https://example.com"},{"url":"ftp://example.com"}]`
+		reqst := httptest.NewRequest("POST", "/api/batch", bytes.NewBufferString(reqBody))
+		reqst.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(reqst, -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		assert.JSONEq(t, `[
+			{"status":200,"url":"http://short.url/b"},
+			{"status":422,"error":"invalid URL: scheme \"ftp\" is not allowed","reason":"scheme_not_allowed"}
+		]`, string(body))
+	})
+
+	// Тест: NDJSON, строка с ошибкой не прерывает обработку
+	t.Run("ndjson", func(t *testing.T) {
+		mockShortenerService.EXPECT().
+			CreateShortURLs(gomock.Any(), []model.Request{{URL: "https://example.com/1"}, {URL: "https://example.com/2"}}).
+			Return([]service.BatchResult{
+				{Response: &model.Response{URL: "http://short.url/b"}},
+				{Err: service.ErrAliasTaken},
+			}, nil)
+
+		reqBody := "{\"url\":\"https://example.com/1\"}\n{broken\n\n{\"url\":\"https://example.com/2\"}\n"
+		reqst := httptest.NewRequest("POST", "/api/batch", bytes.NewBufferString(reqBody))
+		reqst.Header.Set("Content-Type", "application/x-ndjson")
+
+		resp, err := app.Test(reqst, -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, `{"status":200,"url":"http://short.url/b"}`+"\n"+
+			`{"status":400,"error":"Invalid request payload"}`+"\n"+
+			`{"status":409,"error":"alias is already taken"}`+"\n", string(body))
+	})
+
+	// Тест: Слишком большой пакет
+	t.Run("too large", func(t *testing.T) {
+		mockShortenerService.EXPECT().
+			CreateShortURLs(gomock.Any(), gomock.Any()).
+			Return(nil, service.ErrBatchTooLarge)
+
+		reqst := httptest.NewRequest("POST", "/api/batch", bytes.NewBufferString(`[{"url":"https://example.com"}]`))
+		reqst.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(reqst, -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusRequestEntityTooLarge, resp.StatusCode)
+	})
+
+	// Тест: Невалидное тело запроса
+	t.Run("invalid payload", func(t *testing.T) {
+		for _, reqBody := range []string{`{"url":"https://example.com"}`, `[{"url":"https://example.com"}] []`, `[{"url":1}]`} {
+			reqst := httptest.NewRequest("POST", "/api/batch", bytes.NewBufferString(reqBody))
+			reqst.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(reqst, -1)
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, reqBody)
+		}
+	})
+
+	// Тест: Чтение прекращается, как только пакет превысил лимит, до вызова сервиса
+	t.Run("item limit while reading", func(t *testing.T) {
+		shortenerController.SetBatchMaxItems(2)
+		defer shortenerController.SetBatchMaxItems(0)
+
+		for contentType, reqBody := range map[string]string{
+			"application/json":     `[{"url":"https://example.com/1"},{"url":"https://example.com/2"},{"url":"https://example.com/3"}]`,
+			"application/x-ndjson": "{\"url\":\"https://example.com/1\"}\n{\"url\":\"https://example.com/2\"}\n{\"url\":\"https://example.com/3\"}\n",
+		} {
+			reqst := httptest.NewRequest("POST", "/api/batch", bytes.NewBufferString(reqBody))
+			reqst.Header.Set("Content-Type", contentType)
+
+			resp, err := app.Test(reqst, -1)
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusRequestEntityTooLarge, resp.StatusCode, contentType)
+			body, _ := io.ReadAll(resp.Body)
+			assert.JSONEq(t, `{"error":"batch is too large: at most 2 items are allowed"}`, string(body))
+		}
+	})
+}
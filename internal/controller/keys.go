@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+	"strconv"
+	"urlShortener/internal/model"
+	"urlShortener/internal/repository"
+	"urlShortener/internal/service"
+)
+
+type KeyController struct {
+	keyService service.KeyServiceInterface
+	logger     *zap.Logger
+}
+
+func NewKeyController(svc service.KeyServiceInterface, logger *zap.Logger) *KeyController {
+	return &KeyController{
+		keyService: svc,
+		logger:     logger,
+	}
+}
+
+func (k *KeyController) CreateKey(c fiber.Ctx) error {
+	var req model.KeyRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request payload"})
+	}
+
+	resp, err := k.keyService.CreateKey(c.Context(), req.Name)
+	if err != nil {
+		if errors.Is(err, service.ErrEmptyKeyName) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		if errors.Is(err, service.ErrKeyNameTaken) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		}
+		k.logger.Error("Failed to create API key", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (k *KeyController) ListKeys(c fiber.Ctx) error {
+	keys, err := k.keyService.ListKeys(c.Context())
+	if err != nil {
+		k.logger.Error("Failed to list API keys", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(keys)
+}
+
+func (k *KeyController) RevokeKey(c fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid key id"})
+	}
+
+	if err := k.keyService.RevokeKey(c.Context(), id); err != nil {
+		if errors.Is(err, repository.ErrKeyNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		}
+		k.logger.Error("Failed to revoke API key", zap.Int("id", id), zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
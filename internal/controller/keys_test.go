@@ -0,0 +1,103 @@
+package controller_test
+
+import (
+	"bytes"
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"urlShortener/internal/controller"
+	"urlShortener/internal/model"
+	"urlShortener/internal/repository"
+	"urlShortener/internal/service"
+	mockService "urlShortener/mocks"
+)
+
+func TestKeyController(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockKeyService := mockService.NewMockKeyServiceInterface(ctrl)
+
+	app := fiber.New()
+
+	keyController := controller.NewKeyController(mockKeyService, logger)
+	app.Post("/keys", keyController.CreateKey)
+	app.Get("/keys", keyController.ListKeys)
+	app.Delete("/keys/:id", keyController.RevokeKey)
+
+	// Тест: Создание ключа возвращает сам ключ один раз
+	t.Run("create", func(t *testing.T) {
+		mockKeyService.EXPECT().
+			CreateKey(gomock.Any(), "marketing").
+			Return(&model.KeyResponse{APIKey: model.APIKey{ID: 1, Name: "marketing", Prefix: "sk_abcde"}, Key: "sk_abcdef"}, nil)
+
+		reqst := httptest.NewRequest("POST", "/keys", bytes.NewBufferString(`{"name":"marketing"}`))
+		reqst.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(reqst, -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+		body, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, string(body), `"key":"sk_abcdef"`)
+	})
+
+	// Тест: Пустое имя ключа
+	t.Run("empty name", func(t *testing.T) {
+		mockKeyService.EXPECT().
+			CreateKey(gomock.Any(), "").
+			Return(nil, service.ErrEmptyKeyName)
+
+		reqst := httptest.NewRequest("POST", "/keys", bytes.NewBufferString(`{}`))
+		reqst.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(reqst, -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+
+	// Тест: Имя уже занято действующим ключом
+	t.Run("name taken", func(t *testing.T) {
+		mockKeyService.EXPECT().
+			CreateKey(gomock.Any(), "marketing").
+			Return(nil, service.ErrKeyNameTaken)
+
+		reqst := httptest.NewRequest("POST", "/keys", bytes.NewBufferString(`{"name":"marketing"}`))
+		reqst.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(reqst, -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+	})
+
+	// Тест: Список ключей не содержит хешей
+	t.Run("list", func(t *testing.T) {
+		mockKeyService.EXPECT().
+			ListKeys(gomock.Any()).
+			Return([]model.APIKey{{ID: 1, Name: "marketing", Prefix: "sk_abcde", Hash: "secret-hash"}}, nil)
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/keys", nil), -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		body, _ := io.ReadAll(resp.Body)
+		assert.NotContains(t, string(body), "secret-hash")
+	})
+
+	// Тест: Отзыв несуществующего ключа
+	t.Run("revoke not found", func(t *testing.T) {
+		mockKeyService.EXPECT().
+			RevokeKey(gomock.Any(), 42).
+			Return(repository.ErrKeyNotFound)
+
+		resp, err := app.Test(httptest.NewRequest("DELETE", "/keys/42", nil), -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -5,9 +5,26 @@ import "github.com/gofiber/fiber/v3"
 func (s *ShortenerController) Register(router fiber.Router) {
 	router.Post("/", s.CreateShortenerURL)
 	router.Get("/:shortenerURL", s.GetOriginalURL)
+	router.Patch("/:shortenerURL", s.UpdateLink)
+	router.Delete("/:shortenerURL", s.DeleteLink)
+	router.Get("/:shortenerURL/stats", s.GetStats)
+	router.Post("/api/batch", s.BatchShortenURLs)
+	router.Post("/api/expand", s.ExpandURLs)
+	router.Get("/api/expand/:shortenerURL", s.ExpandURL)
+	router.Get("/api/links", s.ListLinks)
 
 }
 
 func (s *ShortenerController) Name() string {
 	return ""
 }
+
+func (k *KeyController) Register(router fiber.Router) {
+	router.Post("/", k.CreateKey)
+	router.Get("/", k.ListKeys)
+	router.Delete("/:id", k.RevokeKey)
+}
+
+func (k *KeyController) Name() string {
+	return "/keys"
+}
@@ -4,13 +4,16 @@ import (
 	"errors"
 	"github.com/gofiber/fiber/v3"
 	"go.uber.org/zap"
+	"time"
 	"urlShortener/internal/model"
 	"urlShortener/internal/repository"
+	httpserver "urlShortener/internal/server_http"
 	"urlShortener/internal/service"
 )
 
 type ShortenerController struct {
 	shortenerService service.ShortenerServiceInterface
+	batchMaxItems    int
 	logger           *zap.Logger
 }
 
@@ -30,28 +33,193 @@ func (s *ShortenerController) CreateShortenerURL(c fiber.Ctx) error {
 	if req.URL == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "URL must not be nil"})
 	}
+	req.Owner = owner(c)
 
-	resp, err := s.shortenerService.CreateShortURL(c.Context(), req.URL)
+	resp, err := s.shortenerService.CreateShortURL(c.Context(), req)
 	if err != nil {
-		s.logger.Error("Failed to create short url", zap.String("url", req.URL), zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		status, reason := createStatus(err)
+		if status == fiber.StatusInternalServerError {
+			s.logger.Error("Failed to create short url", zap.String("url", req.URL), zap.Error(err))
+		}
+		return c.Status(status).JSON(errorBody(err, reason))
 	}
 
 	return c.Status(fiber.StatusOK).JSON(resp)
 }
 
+// GetOriginalURL redirects browsers to the original URL. Clients that
+// prefer application/json get the link body instead, same as ExpandURL.
+// Both answers vary by Accept, so shared caches must keep them apart.
 func (s *ShortenerController) GetOriginalURL(c fiber.Ctx) error {
+	c.Vary(fiber.HeaderAccept)
+	if c.Accepts(fiber.MIMETextHTML, fiber.MIMEApplicationJSON) == fiber.MIMEApplicationJSON {
+		return s.ExpandURL(c)
+	}
 
 	shortenerURL := c.Params("shortenerURL")
 
-	resp, err := s.shortenerService.GetOriginalURL(c.Context(), shortenerURL)
+	link, err := s.shortenerService.GetOriginalURL(c.Context(), shortenerURL)
 	if err != nil {
-		if errors.Is(err, repository.ErrLinkNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": repository.ErrLinkNotFound.Error()})
+		return s.linkError(c, shortenerURL, err)
+	}
+
+	s.shortenerService.RecordClick(model.Click{
+		ShortURL:  link.ShortURL,
+		ClickedAt: time.Now(),
+		Referrer:  c.Get(fiber.HeaderReferer),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		IP:        httpserver.ClientIP(c),
+	})
+
+	return c.Redirect().Status(link.RedirectType).To(link.OriginalURL)
+}
+
+func (s *ShortenerController) GetStats(c fiber.Ctx) error {
+	shortenerURL := c.Params("shortenerURL")
+
+	stats, err := s.shortenerService.GetStats(c.Context(), shortenerURL, owner(c))
+	if err != nil {
+		return s.linkError(c, shortenerURL, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(stats)
+}
+
+func (s *ShortenerController) ExpandURL(c fiber.Ctx) error {
+	shortenerURL := c.Params("shortenerURL")
+
+	link, err := s.shortenerService.GetOriginalURL(c.Context(), shortenerURL)
+	if err != nil {
+		return s.linkError(c, shortenerURL, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.Response{URL: link.OriginalURL})
+}
+
+// ExpandURLs resolves the codes of {"codes": [...]} to a map of code to result.
+func (s *ShortenerController) ExpandURLs(c fiber.Ctx) error {
+	var req model.ExpandRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request payload"})
+	}
+
+	results, err := s.shortenerService.ExpandURLs(c.Context(), req.Codes)
+	if err != nil {
+		if errors.Is(err, service.ErrBatchTooLarge) {
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{"error": err.Error()})
 		}
-		s.logger.Error("some error occurred", zap.String("shortenerURL", shortenerURL), zap.Error(err))
+		s.logger.Error("Failed to expand urls", zap.Int("count", len(req.Codes)), zap.Error(err))
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	return c.Status(fiber.StatusOK).JSON(results)
+}
+
+func (s *ShortenerController) UpdateLink(c fiber.Ctx) error {
+	shortenerURL := c.Params("shortenerURL")
+
+	var req model.UpdateRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request payload"})
+	}
+	req.Owner = owner(c)
+
+	resp, err := s.shortenerService.UpdateLink(c.Context(), shortenerURL, req)
+	if err != nil {
+		return s.linkError(c, shortenerURL, err)
+	}
+
 	return c.Status(fiber.StatusOK).JSON(resp)
 }
+
+func (s *ShortenerController) DeleteLink(c fiber.Ctx) error {
+	shortenerURL := c.Params("shortenerURL")
+
+	if err := s.shortenerService.DeleteLink(c.Context(), shortenerURL, owner(c)); err != nil {
+		return s.linkError(c, shortenerURL, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (s *ShortenerController) ListLinks(c fiber.Ctx) error {
+	var req model.ListRequest
+	if err := c.Bind().Query(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid query parameters"})
+	}
+	req.Owner = owner(c)
+
+	page, err := s.shortenerService.ListLinks(c.Context(), req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidListQuery) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		s.logger.Error("Failed to list links", zap.String("owner", req.Owner), zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(page)
+}
+
+// owner is the name of the API key the request was authenticated with,
+// empty when authentication is disabled.
+func owner(c fiber.Ctx) string {
+	if key, ok := c.Locals(httpserver.APIKeyLocal).(*model.APIKey); ok && key != nil {
+		return key.Name
+	}
+	return ""
+}
+
+// createStatus maps a CreateShortURL error to its HTTP status and, for
+// rejected URLs, the machine-readable reason.
+func createStatus(err error) (int, string) {
+	var urlErr *service.URLError
+	if errors.As(err, &urlErr) {
+		if urlErr.Malformed() {
+			return fiber.StatusBadRequest, urlErr.Reason
+		}
+		return fiber.StatusUnprocessableEntity, urlErr.Reason
+	}
+	var blocked *service.BlockedError
+	if errors.As(err, &blocked) {
+		return fiber.StatusForbidden, blocked.Reason
+	}
+	if errors.Is(err, service.ErrInvalidRedirectType) || errors.Is(err, service.ErrInvalidAlias) ||
+		errors.Is(err, service.ErrInvalidExpiry) || errors.Is(err, service.ErrEmptyURL) {
+		return fiber.StatusBadRequest, ""
+	}
+	if errors.Is(err, service.ErrAliasTaken) {
+		return fiber.StatusConflict, ""
+	}
+	return fiber.StatusInternalServerError, ""
+}
+
+func errorBody(err error, reason string) fiber.Map {
+	body := fiber.Map{"error": err.Error()}
+	if reason != "" {
+		body["reason"] = reason
+	}
+	return body
+}
+
+func (s *ShortenerController) linkError(c fiber.Ctx, shortenerURL string, err error) error {
+	if errors.Is(err, service.ErrInvalidURL) || errors.Is(err, service.ErrURLBlocked) {
+		status, reason := createStatus(err)
+		return c.Status(status).JSON(errorBody(err, reason))
+	}
+	if errors.Is(err, repository.ErrLinkNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": repository.ErrLinkNotFound.Error()})
+	}
+	if errors.Is(err, service.ErrForbidden) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, service.ErrLinkExpired) || errors.Is(err, service.ErrLinkDeleted) {
+		return c.Status(fiber.StatusGone).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, service.ErrInvalidRedirectType) || errors.Is(err, service.ErrInvalidExpiry) ||
+		errors.Is(err, service.ErrEmptyURL) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	s.logger.Error("some error occurred", zap.String("shortenerURL", shortenerURL), zap.Error(err))
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
@@ -11,9 +11,12 @@ import (
 	"io"
 	"net/http/httptest"
 	"testing"
+	"time"
 	"urlShortener/internal/controller"
 	"urlShortener/internal/model"
 	"urlShortener/internal/repository"
+	httpserver "urlShortener/internal/server_http"
+	"urlShortener/internal/service"
 	mockService "urlShortener/mocks"
 )
 
@@ -34,7 +37,7 @@ func TestCreateShortURL(t *testing.T) {
 		req := &model.Request{URL: "http://example.com"}
 
 		mockShortenerService.EXPECT().
-			CreateShortURL(gomock.Any(), req.URL).
+			CreateShortURL(gomock.Any(), *req).
 			Return(&model.Response{URL: "http://short.url/abc123"}, nil)
 
 		// Создаем новый запрос
@@ -72,7 +75,7 @@ func TestCreateShortURL(t *testing.T) {
 		req := model.Request{URL: "https://example.com"}
 
 		mockShortenerService.EXPECT().
-			CreateShortURL(gomock.Any(), req.URL).
+			CreateShortURL(gomock.Any(), req).
 			Return(nil, errors.New("internal error"))
 
 		reqBody := `{"url":"https://example.com"}`
@@ -88,6 +91,92 @@ func TestCreateShortURL(t *testing.T) {
 		assert.JSONEq(t, `{"error":"internal error"}`, string(body))
 	})
 
+	t.Run("invalid redirect type", func(t *testing.T) {
+		req := model.Request{URL: "https://example.com", RedirectType: 200}
+
+		mockShortenerService.EXPECT().
+			CreateShortURL(gomock.Any(), req).
+			Return(nil, service.ErrInvalidRedirectType)
+
+		reqBody := `{"url":"https://example.com","redirect_type":200}`
+		reqst := httptest.NewRequest("POST", "/", bytes.NewBufferString(reqBody))
+		reqst.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(reqst, -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("alias taken", func(t *testing.T) {
+		req := model.Request{URL: "https://example.com", Alias: "spring-sale"}
+
+		mockShortenerService.EXPECT().
+			CreateShortURL(gomock.Any(), req).
+			Return(nil, service.ErrAliasTaken)
+
+		reqBody := `{"url":"https://example.com","alias":"spring-sale"}`
+		reqst := httptest.NewRequest("POST", "/", bytes.NewBufferString(reqBody))
+		reqst.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(reqst, -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		assert.JSONEq(t, `{"error":"alias is already taken"}`, string(body))
+	})
+
+	t.Run("invalid url", func(t *testing.T) {
+		req := model.Request{URL: "javascript:alert(1)"}
+
+		mockShortenerService.EXPECT().
+			CreateShortURL(gomock.Any(), req).
+			Return(nil, &service.URLError{Reason: service.ReasonSchemeNotAllowed, Message: `scheme "javascript" is not allowed`})
+
+		reqBody := `{"url":"javascript:alert(1)"}`
+		reqst := httptest.NewRequest("POST", "/", bytes.NewBufferString(reqBody))
+		reqst.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(reqst, -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusUnprocessableEntity, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		assert.JSONEq(t, `{"error":"invalid URL: scheme \"javascript\" is not allowed","reason":"scheme_not_allowed"}`, string(body))
+	})
+
+	t.Run("blocked url", func(t *testing.T) {
+		req := model.Request{URL: "https://evil.example/"}
+
+		mockShortenerService.EXPECT().
+			CreateShortURL(gomock.Any(), req).
+			Return(nil, &service.BlockedError{Reason: service.ReasonBlockedDomain, Message: `domain "evil.example" is blocked`})
+
+		reqBody := `{"url":"https://evil.example/"}`
+		reqst := httptest.NewRequest("POST", "/", bytes.NewBufferString(reqBody))
+		reqst.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(reqst, -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		assert.JSONEq(t, `{"error":"URL is blocked: domain \"evil.example\" is blocked","reason":"blocked_domain"}`, string(body))
+	})
+
+	t.Run("malformed url", func(t *testing.T) {
+		req := model.Request{URL: "http://exa mple.com"}
+
+		mockShortenerService.EXPECT().
+			CreateShortURL(gomock.Any(), req).
+			Return(nil, &service.URLError{Reason: service.ReasonMalformed, Message: "URL cannot be parsed"})
+
+		reqBody := `{"url":"http://exa mple.com"}`
+		reqst := httptest.NewRequest("POST", "/", bytes.NewBufferString(reqBody))
+		reqst.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(reqst, -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+
 	t.Run("URL must not be nil", func(t *testing.T) {
 		reqBody := `{"URL": ""}`
 
@@ -113,9 +202,44 @@ func TestGetOriginalURL(t *testing.T) {
 	// Создаем Fiber приложение
 	app := fiber.New()
 
+	// Адрес клиента за прокси определяет сервер до контроллера
+	app.Use(func(c fiber.Ctx) error {
+		c.Locals(httpserver.ClientIPLocal, "203.0.113.7")
+		return c.Next()
+	})
+
 	// Инициализируем контроллер с мок сервисом
 	shortenerController := controller.NewShortenerController(mockShortenerService, logger)
 	app.Get("/:shortenerURL", shortenerController.GetOriginalURL)
+	app.Get("/api/expand/:shortenerURL", shortenerController.ExpandURL)
+
+	// Тест: Редирект на оригинальную ссылку
+	t.Run("Redirect", func(t *testing.T) {
+		shortenerURL := "abc123"
+
+		mockShortenerService.EXPECT().
+			GetOriginalURL(gomock.Any(), shortenerURL).
+			Return(&model.Link{ShortURL: shortenerURL, OriginalURL: "https://example.com", RedirectType: fiber.StatusMovedPermanently}, nil)
+
+		mockShortenerService.EXPECT().
+			RecordClick(gomock.Any()).
+			Do(func(click model.Click) {
+				assert.Equal(t, shortenerURL, click.ShortURL)
+				assert.Equal(t, "https://ref.example", click.Referrer)
+				assert.Equal(t, "203.0.113.7", click.IP)
+			})
+
+		reqst := httptest.NewRequest("GET", "/abc123", nil)
+		reqst.Header.Set("Accept", "text/html,application/xhtml+xml,*/*;q=0.8")
+		reqst.Header.Set("Referer", "https://ref.example")
+
+		resp, err := app.Test(reqst, -1)
+		require.NoError(t, err)
+
+		assert.Equal(t, fiber.StatusMovedPermanently, resp.StatusCode)
+		assert.Equal(t, "https://example.com", resp.Header.Get("Location"))
+		assert.Equal(t, "Accept", resp.Header.Get("Vary"))
+	})
 
 	// Тест: Успешное получение оригинальной ссылки
 	t.Run("Success", func(t *testing.T) {
@@ -124,9 +248,30 @@ func TestGetOriginalURL(t *testing.T) {
 		// Определяем поведение мока
 		mockShortenerService.EXPECT().
 			GetOriginalURL(gomock.Any(), shortenerURL).
-			Return(&model.Response{URL: "https://example.com"}, nil)
+			Return(&model.Link{ShortURL: shortenerURL, OriginalURL: "https://example.com", RedirectType: fiber.StatusFound}, nil)
 
 		reqst := httptest.NewRequest("GET", "/abc123", nil)
+		reqst.Header.Set("Accept", "application/json")
+
+		resp, err := app.Test(reqst, -1)
+		require.NoError(t, err)
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, "Accept", resp.Header.Get("Vary"))
+
+		body, _ := io.ReadAll(resp.Body)
+		assert.JSONEq(t, `{"url":"https://example.com"}`, string(body))
+	})
+
+	// Тест: JSON-ответ через отдельный эндпоинт
+	t.Run("Expand", func(t *testing.T) {
+		shortenerURL := "abc123"
+
+		mockShortenerService.EXPECT().
+			GetOriginalURL(gomock.Any(), shortenerURL).
+			Return(&model.Link{ShortURL: shortenerURL, OriginalURL: "https://example.com", RedirectType: fiber.StatusFound}, nil)
+
+		reqst := httptest.NewRequest("GET", "/api/expand/abc123", nil)
 
 		resp, err := app.Test(reqst, -1)
 		require.NoError(t, err)
@@ -156,6 +301,25 @@ func TestGetOriginalURL(t *testing.T) {
 		assert.JSONEq(t, `{"error":"link not found"}`, string(body))
 	})
 
+	// Тест: Ссылка с истекшим сроком действия
+	t.Run("link expired", func(t *testing.T) {
+		shortenerURL := "expired"
+
+		mockShortenerService.EXPECT().
+			GetOriginalURL(gomock.Any(), shortenerURL).
+			Return(nil, service.ErrLinkExpired)
+
+		reqst := httptest.NewRequest("GET", "/expired", nil)
+
+		resp, err := app.Test(reqst, -1)
+		require.NoError(t, err)
+
+		assert.Equal(t, fiber.StatusGone, resp.StatusCode)
+
+		body, _ := io.ReadAll(resp.Body)
+		assert.JSONEq(t, `{"error":"link expired"}`, string(body))
+	})
+
 	// Тест: Ошибка сервиса при получении ссылки
 	t.Run("service error", func(t *testing.T) {
 		shortenerURL := "abc123"
@@ -175,3 +339,256 @@ func TestGetOriginalURL(t *testing.T) {
 		assert.JSONEq(t, `{"error":"internal error"}`, string(body))
 	})
 }
+
+func TestGetStats(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockShortenerService := mockService.NewMockShortenerServiceInterface(ctrl)
+
+	app := fiber.New()
+
+	shortenerController := controller.NewShortenerController(mockShortenerService, logger)
+	app.Get("/:shortenerURL/stats", shortenerController.GetStats)
+
+	// Тест: Успешное получение статистики
+	t.Run("Success", func(t *testing.T) {
+		mockShortenerService.EXPECT().
+			GetStats(gomock.Any(), "abc123", "").
+			Return(&model.Stats{
+				ShortURL: "abc123",
+				Total:    3,
+				Daily:    []model.DailyClicks{{Date: "2026-10-17", Count: 1}, {Date: "2026-10-18", Count: 2}},
+			}, nil)
+
+		reqst := httptest.NewRequest("GET", "/abc123/stats", nil)
+
+		resp, err := app.Test(reqst, -1)
+		require.NoError(t, err)
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		body, _ := io.ReadAll(resp.Body)
+		assert.JSONEq(t, `{"short_url":"abc123","total":3,"daily":[{"date":"2026-10-17","count":1},{"date":"2026-10-18","count":2}]}`, string(body))
+	})
+
+	// Тест: Статистика несуществующей ссылки
+	t.Run("link not found", func(t *testing.T) {
+		mockShortenerService.EXPECT().
+			GetStats(gomock.Any(), "notfound", "").
+			Return(nil, repository.ErrLinkNotFound)
+
+		reqst := httptest.NewRequest("GET", "/notfound/stats", nil)
+
+		resp, err := app.Test(reqst, -1)
+		require.NoError(t, err)
+
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+
+	// Тест: Статистика чужой ссылки
+	t.Run("forbidden", func(t *testing.T) {
+		mockShortenerService.EXPECT().
+			GetStats(gomock.Any(), "foreign", "").
+			Return(nil, service.ErrForbidden)
+
+		reqst := httptest.NewRequest("GET", "/foreign/stats", nil)
+
+		resp, err := app.Test(reqst, -1)
+		require.NoError(t, err)
+
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+	})
+}
+
+func TestUpdateLink(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockShortenerService := mockService.NewMockShortenerServiceInterface(ctrl)
+
+	app := fiber.New()
+
+	shortenerController := controller.NewShortenerController(mockShortenerService, logger)
+	app.Patch("/:shortenerURL", shortenerController.UpdateLink)
+
+	// Тест: Успешное изменение ссылки
+	t.Run("Success", func(t *testing.T) {
+		target := "https://example.org"
+		redirectType := 308
+
+		mockShortenerService.EXPECT().
+			UpdateLink(gomock.Any(), "abc123", model.UpdateRequest{URL: &target, RedirectType: &redirectType}).
+			Return(&model.Response{URL: "http://short.url/abc123"}, nil)
+
+		reqBody := `{"url":"https://example.org","redirect_type":308}`
+		reqst := httptest.NewRequest("PATCH", "/abc123", bytes.NewBufferString(reqBody))
+		reqst.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(reqst, -1)
+		require.NoError(t, err)
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		assert.JSONEq(t, `{"url":"http://short.url/abc123"}`, string(body))
+	})
+
+	// Тест: Изменение удалённой ссылки
+	t.Run("link deleted", func(t *testing.T) {
+		mockShortenerService.EXPECT().
+			UpdateLink(gomock.Any(), "deleted", gomock.Any()).
+			Return(nil, service.ErrLinkDeleted)
+
+		reqst := httptest.NewRequest("PATCH", "/deleted", bytes.NewBufferString(`{"permanent":true}`))
+		reqst.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(reqst, -1)
+		require.NoError(t, err)
+
+		assert.Equal(t, fiber.StatusGone, resp.StatusCode)
+	})
+
+	// Тест: Некорректный срок действия
+	t.Run("invalid expiry", func(t *testing.T) {
+		mockShortenerService.EXPECT().
+			UpdateLink(gomock.Any(), "abc123", model.UpdateRequest{TTL: "-1h"}).
+			Return(nil, service.ErrInvalidExpiry)
+
+		reqst := httptest.NewRequest("PATCH", "/abc123", bytes.NewBufferString(`{"ttl":"-1h"}`))
+		reqst.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(reqst, -1)
+		require.NoError(t, err)
+
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestDeleteLink(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockShortenerService := mockService.NewMockShortenerServiceInterface(ctrl)
+
+	app := fiber.New()
+
+	shortenerController := controller.NewShortenerController(mockShortenerService, logger)
+	app.Delete("/:shortenerURL", shortenerController.DeleteLink)
+
+	// Тест: Успешное удаление
+	t.Run("Success", func(t *testing.T) {
+		mockShortenerService.EXPECT().
+			DeleteLink(gomock.Any(), "abc123", "").
+			Return(nil)
+
+		resp, err := app.Test(httptest.NewRequest("DELETE", "/abc123", nil), -1)
+		require.NoError(t, err)
+
+		assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	})
+
+	// Тест: Удаление несуществующей ссылки
+	t.Run("link not found", func(t *testing.T) {
+		mockShortenerService.EXPECT().
+			DeleteLink(gomock.Any(), "notfound", "").
+			Return(repository.ErrLinkNotFound)
+
+		resp, err := app.Test(httptest.NewRequest("DELETE", "/notfound", nil), -1)
+		require.NoError(t, err)
+
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestListLinks(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockShortenerService := mockService.NewMockShortenerServiceInterface(ctrl)
+
+	app := fiber.New()
+
+	shortenerController := controller.NewShortenerController(mockShortenerService, logger)
+	app.Use(func(c fiber.Ctx) error {
+		c.Locals(httpserver.APIKeyLocal, &model.APIKey{ID: 1, Name: "marketing"})
+		return c.Next()
+	})
+	app.Get("/api/links", shortenerController.ListLinks)
+
+	// Тест: Список ссылок владельца ключа
+	t.Run("Success", func(t *testing.T) {
+		createdAt := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+		mockShortenerService.EXPECT().
+			ListLinks(gomock.Any(), model.ListRequest{Owner: "marketing", Limit: 1, Host: "example.com", Sort: "created_at"}).
+			Return(&model.LinkPage{
+				Links:      []model.Link{{ID: 1, ShortURL: "spring-sale", OriginalURL: "https://example.com/sale", Owner: "marketing", CreatedAt: createdAt}},
+				NextCursor: "next",
+			}, nil)
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/links?limit=1&host=example.com&sort=created_at", nil), -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		body, _ := io.ReadAll(resp.Body)
+		assert.JSONEq(t, `{"links":[{"id":1,"code":"spring-sale","original_url":"https://example.com/sale","owner":"marketing","created_at":"2026-10-18T12:00:00Z"}],"next_cursor":"next"}`, string(body))
+	})
+
+	// Тест: Некорректные параметры
+	t.Run("invalid query", func(t *testing.T) {
+		mockShortenerService.EXPECT().
+			ListLinks(gomock.Any(), model.ListRequest{Owner: "marketing", Cursor: "garbage"}).
+			Return(nil, service.ErrInvalidListQuery)
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/links?cursor=garbage", nil), -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestExpandURLs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockShortenerService := mockService.NewMockShortenerServiceInterface(ctrl)
+
+	app := fiber.New()
+	shortenerController := controller.NewShortenerController(mockShortenerService, zap.NewNop())
+	app.Post("/api/expand", shortenerController.ExpandURLs)
+
+	t.Run("Success", func(t *testing.T) {
+		mockShortenerService.EXPECT().
+			ExpandURLs(gomock.Any(), []string{"abc", "zzz"}).
+			Return(map[string]model.ExpandResult{
+				"abc": {Status: model.ExpandFound, URL: "https://example.com"},
+				"zzz": {Status: model.ExpandNotFound},
+			}, nil)
+
+		reqst := httptest.NewRequest("POST", "/api/expand", bytes.NewBufferString(`{"codes":["abc","zzz"]}`))
+		reqst.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(reqst, -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		assert.JSONEq(t, `{"abc":{"status":"found","url":"https://example.com"},"zzz":{"status":"not_found"}}`, string(body))
+	})
+
+	// Тест: Слишком много кодов
+	t.Run("too many codes", func(t *testing.T) {
+		mockShortenerService.EXPECT().
+			ExpandURLs(gomock.Any(), gomock.Any()).
+			Return(nil, service.ErrBatchTooLarge)
+
+		reqst := httptest.NewRequest("POST", "/api/expand", bytes.NewBufferString(`{"codes":["abc"]}`))
+		reqst.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(reqst, -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusRequestEntityTooLarge, resp.StatusCode)
+	})
+}
@@ -1,20 +1,73 @@
 package initialize
 
 import (
+	"fmt"
 	"github.com/caarlos0/env/v8"
 	"github.com/joho/godotenv"
 	"log"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+	"urlShortener/internal/model"
 )
 
 type Config struct {
-	HTTPHost        string `env:"HTTP_HOST" envDefault:"localhost"`
-	HTTPPort        string `env:"HTTP_PORT" envDefault:"3000"`
-	PGMaxAttemption int    `env:"PG_MAX_ATTEMPTION" envDefault:"5"`
-	PGHost          string `env:"PG_HOST" envDefault:"localhost"`
-	PGPort          string `env:"PG_PORT" envDefault:"5432"`
-	PGUser          string `env:"PG_USER" envDefault:"postgres"`
-	PGPassword      string `env:"PG_PASSWORD" envDefault:"22578"`
-	PGDatabase      string `env:"PG_DATABASE" envDefault:"urlshortener"`
+	HTTPHost               string        `env:"HTTP_HOST" envDefault:"localhost"`
+	HTTPPort               string        `env:"HTTP_PORT" envDefault:"3000"`
+	PublicBaseURL          string        `env:"PUBLIC_BASE_URL"`
+	TrustedProxies         []string      `env:"TRUSTED_PROXIES" envSeparator:","`
+	MetricsEnabled         bool          `env:"METRICS_ENABLED" envDefault:"true"`
+	MetricsHost            string        `env:"METRICS_HOST" envDefault:"localhost"`
+	MetricsPort            string        `env:"METRICS_PORT" envDefault:"9090"`
+	AliasDomains           []string      `env:"ALIAS_DOMAINS" envSeparator:","`
+	SelfLinks              string        `env:"SELF_LINKS" envDefault:"resolve"`
+	SelfLinkMaxDepth       int           `env:"SELF_LINK_MAX_DEPTH" envDefault:"5"`
+	AuthRequired           bool          `env:"AUTH_REQUIRED" envDefault:"true"`
+	AdminToken             string        `env:"ADMIN_TOKEN"`
+	APIKeys                []string      `env:"API_KEYS" envSeparator:","`
+	StorageBackend         string        `env:"STORAGE_BACKEND" envDefault:"postgres"`
+	PGMaxAttemption        int           `env:"PG_MAX_ATTEMPTION" envDefault:"5"`
+	PGHost                 string        `env:"PG_HOST" envDefault:"localhost"`
+	PGPort                 string        `env:"PG_PORT" envDefault:"5432"`
+	PGUser                 string        `env:"PG_USER" envDefault:"postgres"`
+	PGPassword             string        `env:"PG_PASSWORD" envDefault:"22578"`
+	PGDatabase             string        `env:"PG_DATABASE" envDefault:"urlshortener"`
+	SQLitePath             string        `env:"SQLITE_PATH" envDefault:"urlshortener.db"`
+	StorageFile            string        `env:"STORAGE_FILE"`
+	StorageCompactInterval time.Duration `env:"STORAGE_COMPACT_INTERVAL" envDefault:"10m"`
+	StorageFsync           bool          `env:"STORAGE_FSYNC" envDefault:"false"`
+	CacheSize              int           `env:"CACHE_SIZE" envDefault:"10000"`
+	CacheTTL               time.Duration `env:"CACHE_TTL" envDefault:"5m"`
+	CacheNegativeTTL       time.Duration `env:"CACHE_NEGATIVE_TTL" envDefault:"10s"`
+	CacheNotifyChannel     string        `env:"CACHE_NOTIFY_CHANNEL" envDefault:"link_changes"`
+	RedirectType           int           `env:"REDIRECT_TYPE" envDefault:"302"`
+	URLSchemes             []string      `env:"URL_SCHEMES" envSeparator:"," envDefault:"http,https"`
+	URLSortQuery           bool          `env:"URL_SORT_QUERY" envDefault:"false"`
+	BlocklistFile          string        `env:"BLOCKLIST_FILE"`
+	BlocklistReload        time.Duration `env:"BLOCKLIST_RELOAD" envDefault:"10s"`
+	ReputationURL          string        `env:"REPUTATION_URL"`
+	ReputationTimeout      time.Duration `env:"REPUTATION_TIMEOUT" envDefault:"2s"`
+	ReputationFailClosed   bool          `env:"REPUTATION_FAIL_CLOSED" envDefault:"false"`
+	BatchMaxItems          int           `env:"BATCH_MAX_ITEMS" envDefault:"10000"`
+	BatchConcurrency       int           `env:"BATCH_CONCURRENCY" envDefault:"16"`
+	Dedupe                 bool          `env:"DEDUPE" envDefault:"true"`
+	CodeGenerator          string        `env:"CODE_GENERATOR" envDefault:"base62"`
+	CodeAlphabet           string        `env:"CODE_ALPHABET"`
+	CodeSecret             string        `env:"CODE_SECRET"`
+	CodeSecretBits         int           `env:"CODE_SECRET_BITS" envDefault:"32"`
+	SweepInterval          time.Duration `env:"SWEEP_INTERVAL" envDefault:"1m"`
+	ClickBuffer            int           `env:"CLICK_BUFFER" envDefault:"10000"`
+	ClickBatchSize         int           `env:"CLICK_BATCH_SIZE" envDefault:"500"`
+	ClickFlush             time.Duration `env:"CLICK_FLUSH" envDefault:"1s"`
+	ClickRingSize          int           `env:"CLICK_RING_SIZE" envDefault:"100000"`
+	RateLimitBackend       string        `env:"RATE_LIMIT_BACKEND" envDefault:"memory"`
+	RateLimitCreate        int           `env:"RATE_LIMIT_CREATE" envDefault:"60"`
+	RateLimitCreateBurst   int           `env:"RATE_LIMIT_CREATE_BURST" envDefault:"20"`
+	RateLimitRedirect      int           `env:"RATE_LIMIT_REDIRECT" envDefault:"1200"`
+	RateLimitRedirectBurst int           `env:"RATE_LIMIT_REDIRECT_BURST" envDefault:"200"`
+	RateLimitAuth          int           `env:"RATE_LIMIT_AUTH" envDefault:"600"`
+	RateLimitAuthBurst     int           `env:"RATE_LIMIT_AUTH_BURST" envDefault:"100"`
 }
 
 func Load() (*Config, error) {
@@ -25,5 +78,105 @@ func Load() (*Config, error) {
 	if err := env.Parse(&config); err != nil {
 		return nil, err
 	}
+	if err := config.validatePublicBaseURL(); err != nil {
+		return nil, err
+	}
+	if err := config.validateAuth(); err != nil {
+		return nil, err
+	}
+	if _, err := config.TrustedProxyPrefixes(); err != nil {
+		return nil, err
+	}
+	if config.StorageBackend != "memory" && config.StorageBackend != "postgres" && config.StorageBackend != "sqlite" {
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q", config.StorageBackend)
+	}
+	if config.SelfLinks != "reject" && config.SelfLinks != "resolve" {
+		return nil, fmt.Errorf("unknown SELF_LINKS %q", config.SelfLinks)
+	}
+	if config.RateLimitBackend != "memory" && config.RateLimitBackend != "postgres" {
+		return nil, fmt.Errorf("unknown RATE_LIMIT_BACKEND %q", config.RateLimitBackend)
+	}
 	return &config, nil
 }
+
+// CreateRateLimit is the per-client budget for mutating requests,
+// RATE_LIMIT_CREATE is counted in requests per minute.
+func (c *Config) CreateRateLimit() model.RateLimit {
+	return perMinute(c.RateLimitCreate, c.RateLimitCreateBurst)
+}
+
+// RedirectRateLimit is the per-client budget for redirects,
+// RATE_LIMIT_REDIRECT is counted in requests per minute.
+func (c *Config) RedirectRateLimit() model.RateLimit {
+	return perMinute(c.RateLimitRedirect, c.RateLimitRedirectBurst)
+}
+
+// AuthRateLimit is the per-IP budget charged before an API key is checked,
+// RATE_LIMIT_AUTH is counted in requests per minute.
+func (c *Config) AuthRateLimit() model.RateLimit {
+	return perMinute(c.RateLimitAuth, c.RateLimitAuthBurst)
+}
+
+func perMinute(rate, burst int) model.RateLimit {
+	return model.RateLimit{Rate: float64(rate) / 60, Burst: burst}
+}
+
+// TrustedProxyPrefixes parses TRUSTED_PROXIES, a list of addresses and CIDR ranges.
+func (c *Config) TrustedProxyPrefixes() ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(c.TrustedProxies))
+	for _, proxy := range c.TrustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if !strings.Contains(proxy, "/") {
+			addr, err := netip.ParseAddr(proxy)
+			if err != nil {
+				return nil, fmt.Errorf("invalid TRUSTED_PROXIES entry %q: %w", proxy, err)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TRUSTED_PROXIES entry %q: %w", proxy, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// BaseURL is the prefix short links are rendered with. Without
+// PUBLIC_BASE_URL it points at the HTTP listener address.
+func (c *Config) BaseURL() string {
+	if c.PublicBaseURL != "" {
+		return strings.TrimRight(c.PublicBaseURL, "/")
+	}
+	return "http://" + c.HTTPHost + ":" + c.HTTPPort
+}
+
+// validateAuth refuses to start a server nobody could create links on:
+// with auth on, a key has to come from API_KEYS or be issued with ADMIN_TOKEN.
+func (c *Config) validateAuth() error {
+	if c.AuthRequired && c.AdminToken == "" && len(c.APIKeys) == 0 {
+		return fmt.Errorf("AUTH_REQUIRED is set but neither ADMIN_TOKEN nor API_KEYS is configured")
+	}
+	return nil
+}
+
+func (c *Config) validatePublicBaseURL() error {
+	if c.PublicBaseURL == "" {
+		return nil
+	}
+	u, err := url.Parse(c.PublicBaseURL)
+	if err != nil {
+		return fmt.Errorf("invalid PUBLIC_BASE_URL: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid PUBLIC_BASE_URL %q: scheme and host are required", c.PublicBaseURL)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("invalid PUBLIC_BASE_URL %q: query and fragment are not allowed", c.PublicBaseURL)
+	}
+	return nil
+}
@@ -2,17 +2,26 @@ package initialize
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/pressly/goose/v3"
 	"go.uber.org/zap"
+	"net/url"
 	"time"
 	"urlShortener/internal/utils"
+	"urlShortener/schema"
+
+	_ "modernc.org/sqlite"
 )
 
+const migrationsDir = "./schema"
+
+// DB holds either a Postgres pool or an SQLite handle, depending on STORAGE_BACKEND.
 type DB struct {
-	Pool *pgxpool.Pool
+	Pool   *pgxpool.Pool
+	SQLite *sql.DB
 }
 
 func NewClient(ctx context.Context, maxAttempts int, config *Config) (*DB, error) {
@@ -53,22 +62,66 @@ func NewClient(ctx context.Context, maxAttempts int, config *Config) (*DB, error
 	return dbInstance, nil
 }
 
+// NewSQLiteClient opens the database file at path, creating it if needed.
+// WAL lets redirects read while a write is in progress, writers wait on
+// each other through busy_timeout instead of failing with SQLITE_BUSY.
+func NewSQLiteClient(ctx context.Context, path string) (*DB, error) {
+	query := url.Values{}
+	query.Add("_pragma", "journal_mode(WAL)")
+	query.Add("_pragma", "busy_timeout(5000)")
+	query.Add("_pragma", "synchronous(NORMAL)")
+	query.Set("_txlock", "immediate")
+	query.Set("_time_format", "sqlite")
+
+	db, err := sql.Open("sqlite", "file:"+path+"?"+query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &DB{
+		SQLite: db,
+	}, nil
+}
+
+// RunMigrations applies ./schema to whichever database the DB was opened
+// with. Both dialects share the chain; the steps they disagree on are Go
+// migrations in package schema, which read the dialect set here.
 func (d *DB) RunMigrations(logger *zap.Logger) error {
-	db := stdlib.OpenDBFromPool(d.Pool)
+	dialect := schema.DialectPostgres
+	var db *sql.DB
+	if d.SQLite != nil {
+		dialect = schema.DialectSQLite
+		db = d.SQLite
+	} else {
+		db = stdlib.OpenDBFromPool(d.Pool)
+	}
 
-	if err := goose.SetDialect("pgx"); err != nil {
+	if err := goose.SetDialect(dialect); err != nil {
 		logger.Error("Error setting goose dialect", zap.Error(err))
 		return err
 	}
-
-	migrationsDir := "./schema"
+	schema.SetDialect(dialect)
 
 	if err := goose.Up(db, migrationsDir); err != nil {
 		logger.Error("Error running migrations", zap.Error(err))
 		return err
 	}
 
-	logger.Info("Migrations successfully applied")
+	logger.Info("Migrations successfully applied", zap.String("dialect", dialect))
 	//fmt.Println("Migrations applied successfully!")
 	return nil
 }
+
+// Close releases whichever connection the DB was opened with.
+func (d *DB) Close() {
+	if d.Pool != nil {
+		d.Pool.Close()
+	}
+	if d.SQLite != nil {
+		d.SQLite.Close()
+	}
+}
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector reads pgxpool.Stat on every scrape.
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns    *prometheus.Desc
+	idleConns        *prometheus.Desc
+	totalConns       *prometheus.Desc
+	maxConns         *prometheus.Desc
+	acquireCount     *prometheus.Desc
+	acquireDuration  *prometheus.Desc
+	emptyAcquire     *prometheus.Desc
+	canceledAcquire  *prometheus.Desc
+	newConns         *prometheus.Desc
+	lifetimeDestroys *prometheus.Desc
+	idleDestroys     *prometheus.Desc
+}
+
+func newPoolCollector(pool *pgxpool.Pool) *poolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "pgxpool", name), help, nil, nil)
+	}
+	return &poolCollector{
+		pool:             pool,
+		acquiredConns:    desc("acquired_conns", "Connections currently checked out of the pool."),
+		idleConns:        desc("idle_conns", "Idle connections in the pool."),
+		totalConns:       desc("total_conns", "Connections in the pool, acquired, idle and being opened."),
+		maxConns:         desc("max_conns", "Maximum size of the pool."),
+		acquireCount:     desc("acquires_total", "Successful connection acquires."),
+		acquireDuration:  desc("acquire_duration_seconds_total", "Time spent waiting for successful acquires."),
+		emptyAcquire:     desc("empty_acquires_total", "Acquires that had to wait because the pool was empty."),
+		canceledAcquire:  desc("canceled_acquires_total", "Acquires canceled by their context."),
+		newConns:         desc("new_conns_total", "Connections opened."),
+		lifetimeDestroys: desc("max_lifetime_destroys_total", "Connections closed for exceeding their maximum lifetime."),
+		idleDestroys:     desc("max_idle_destroys_total", "Connections closed for staying idle too long."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+	gauge := func(desc *prometheus.Desc, value float64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value)
+	}
+	counter := func(desc *prometheus.Desc, value float64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, value)
+	}
+
+	gauge(c.acquiredConns, float64(stat.AcquiredConns()))
+	gauge(c.idleConns, float64(stat.IdleConns()))
+	gauge(c.totalConns, float64(stat.TotalConns()))
+	gauge(c.maxConns, float64(stat.MaxConns()))
+	counter(c.acquireCount, float64(stat.AcquireCount()))
+	counter(c.acquireDuration, stat.AcquireDuration().Seconds())
+	counter(c.emptyAcquire, float64(stat.EmptyAcquireCount()))
+	counter(c.canceledAcquire, float64(stat.CanceledAcquireCount()))
+	counter(c.newConns, float64(stat.NewConnsCount()))
+	counter(c.lifetimeDestroys, float64(stat.MaxLifetimeDestroyCount()))
+	counter(c.idleDestroys, float64(stat.MaxIdleDestroyCount()))
+}
@@ -1,9 +1,202 @@
 package model
 
+import (
+	"math"
+	"time"
+)
+
 type Request struct {
-	URL string `json:"url"`
+	URL          string     `json:"url"`
+	Alias        string     `json:"alias"`
+	RedirectType int        `json:"redirect_type"`
+	TTL          string     `json:"ttl"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+	Dedupe       *bool      `json:"dedupe"`
+	Owner        string     `json:"-"`
+}
+
+// BatchItem is the result of one item of POST /api/batch, at the same position as its request.
+type BatchItem struct {
+	Status    int        `json:"status"`
+	URL       string     `json:"url,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	Reason    string     `json:"reason,omitempty"`
+}
+
+type ExpandRequest struct {
+	Codes []string `json:"codes"`
+}
+
+// Statuses of a code in an ExpandResult.
+const (
+	ExpandFound    = "found"
+	ExpandNotFound = "not_found"
+	ExpandExpired  = "expired"
+	ExpandDeleted  = "deleted"
+	ExpandBlocked  = "blocked"
+)
+
+type ExpandResult struct {
+	Status string `json:"status"`
+	URL    string `json:"url,omitempty"`
+}
+
+type UpdateRequest struct {
+	URL          *string    `json:"url"`
+	RedirectType *int       `json:"redirect_type"`
+	TTL          string     `json:"ttl"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+	Permanent    bool       `json:"permanent"`
+	Owner        string     `json:"-"`
 }
 
 type Response struct {
-	URL string `json:"url"`
+	URL       string     `json:"url"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type Link struct {
+	ID           int        `json:"id"`
+	ShortURL     string     `json:"code"`
+	OriginalURL  string     `json:"original_url"`
+	RedirectType int        `json:"redirect_type,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	DeletedAt    *time.Time `json:"-"`
+	Owner        string     `json:"owner,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+func (l *Link) Expired(now time.Time) bool {
+	return l.ExpiresAt != nil && !now.Before(*l.ExpiresAt)
+}
+
+type ListRequest struct {
+	Owner         string `query:"-"`
+	Cursor        string `query:"cursor"`
+	Limit         int    `query:"limit"`
+	Sort          string `query:"sort"`
+	Host          string `query:"host"`
+	CreatedAfter  string `query:"created_after"`
+	CreatedBefore string `query:"created_before"`
+}
+
+// ListQuery selects a page of live links. An empty Owner matches every owner.
+// After is the (CreatedAt, ID) position of the last link on the previous page.
+type ListQuery struct {
+	Owner         string
+	Host          string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Desc          bool
+	After         *LinkCursor
+	Limit         int
+}
+
+type LinkCursor struct {
+	CreatedAt time.Time
+	ID        int
+}
+
+type LinkPage struct {
+	Links      []Link `json:"links"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type Click struct {
+	ShortURL  string
+	ClickedAt time.Time
+	Referrer  string
+	UserAgent string
+	IP        string
+}
+
+type Stats struct {
+	ShortURL string        `json:"short_url"`
+	Total    int64         `json:"total"`
+	Daily    []DailyClicks `json:"daily"`
+}
+
+type DailyClicks struct {
+	Date  string `json:"date"`
+	Count int64  `json:"count"`
+}
+
+type APIKey struct {
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`
+	Hash      string     `json:"-"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+type KeyRequest struct {
+	Name string `json:"name"`
+}
+
+// KeyResponse carries the raw key, it is only shown once on creation.
+type KeyResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// RateLimit is a token bucket: Burst tokens at most, refilled at Rate tokens per second.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+func (l RateLimit) Enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// Refill returns the tokens a bucket holds after elapsed time, capped at Burst.
+func (l RateLimit) Refill(tokens float64, elapsed time.Duration) float64 {
+	if elapsed > 0 {
+		tokens += elapsed.Seconds() * l.Rate
+	}
+	return math.Min(tokens, float64(l.Burst))
+}
+
+// FillTime is how long an empty bucket takes to become full again.
+func (l RateLimit) FillTime() time.Duration {
+	return l.wait(float64(l.Burst))
+}
+
+// Need is what a bucket must hold to admit a request costing cost tokens.
+// A cost above Burst waits for a full bucket and leaves it in debt, so a
+// large batch still passes while the long-run rate stays at Rate.
+func (l RateLimit) Need(cost int) float64 {
+	return float64(min(cost, l.Burst))
+}
+
+// Decision describes a bucket holding tokens after a request that needed
+// need tokens was allowed or rejected.
+func (l RateLimit) Decision(allowed bool, tokens float64, need float64) RateDecision {
+	decision := RateDecision{
+		Allowed:   allowed,
+		Limit:     l.Burst,
+		Remaining: int(math.Max(0, math.Floor(tokens))),
+		Reset:     l.wait(float64(l.Burst) - tokens),
+	}
+	if !allowed {
+		decision.RetryAfter = l.wait(need - tokens)
+	}
+	return decision
+}
+
+func (l RateLimit) wait(tokens float64) time.Duration {
+	if tokens <= 0 || l.Rate <= 0 {
+		return 0
+	}
+	return time.Duration(tokens / l.Rate * float64(time.Second))
+}
+
+type RateDecision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
 }
@@ -0,0 +1,262 @@
+package repository
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"sync"
+	"sync/atomic"
+	"time"
+	"urlShortener/internal/model"
+)
+
+type CacheConfig struct {
+	Size        int           // entries kept, the least recently used one is evicted first
+	TTL         time.Duration // how long a found link is served from memory
+	NegativeTTL time.Duration // how long a miss is remembered, 0 disables negative caching
+}
+
+type CacheStats struct {
+	Hits         uint64
+	NegativeHits uint64
+	Misses       uint64
+	Evictions    uint64
+}
+
+// cacheEntry is a cached lookup, link is nil for a remembered miss.
+type cacheEntry struct {
+	shortURL  string
+	link      *model.Link
+	expiresAt time.Time
+}
+
+// CachingRepository serves GetOriginalURL from a bounded LRU in front of
+// another SwapRepository. Writes that go through it evict the codes they
+// touch, everything else is passed through.
+type CachingRepository struct {
+	SwapRepository
+	config CacheConfig
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List // front is the most recently used
+	// generation is bumped by every invalidation. A lookup that raced with
+	// one does not store its result, which may predate the write.
+	generation uint64
+
+	hits         atomic.Uint64
+	negativeHits atomic.Uint64
+	misses       atomic.Uint64
+	evictions    atomic.Uint64
+	logger       *zap.Logger
+}
+
+func NewCachingRepository(repo SwapRepository, config CacheConfig, logger *zap.Logger) *CachingRepository {
+	if config.Size <= 0 {
+		config.Size = 1
+	}
+	return &CachingRepository{
+		SwapRepository: repo,
+		config:         config,
+		now:            time.Now,
+		entries:        make(map[string]*list.Element, config.Size),
+		order:          list.New(),
+		logger:         logger,
+	}
+}
+
+func (c *CachingRepository) GetOriginalURL(ctx context.Context, shortURL string) (*model.Link, error) {
+	if entry, ok := c.get(shortURL); ok {
+		if entry.link == nil {
+			c.negativeHits.Add(1)
+			return nil, ErrLinkNotFound
+		}
+		c.hits.Add(1)
+		link := *entry.link
+		return &link, nil
+	}
+	c.misses.Add(1)
+
+	generation := c.currentGeneration()
+	link, err := c.SwapRepository.GetOriginalURL(ctx, shortURL)
+	switch {
+	case err == nil:
+		cached := *link
+		c.put(shortURL, &cached, c.config.TTL, generation)
+	case errors.Is(err, ErrLinkNotFound) && c.config.NegativeTTL > 0:
+		c.put(shortURL, nil, c.config.NegativeTTL, generation)
+	}
+	return link, err
+}
+
+// GetOriginalURLs answers cached codes from memory and looks up the rest
+// in one call to the underlying repository.
+func (c *CachingRepository) GetOriginalURLs(ctx context.Context, shortURLs []string) (map[string]model.Link, error) {
+	links := make(map[string]model.Link, len(shortURLs))
+	var missing []string
+	for _, shortURL := range shortURLs {
+		entry, ok := c.get(shortURL)
+		switch {
+		case !ok:
+			c.misses.Add(1)
+			missing = append(missing, shortURL)
+		case entry.link == nil:
+			c.negativeHits.Add(1)
+		default:
+			c.hits.Add(1)
+			links[shortURL] = *entry.link
+		}
+	}
+	if len(missing) == 0 {
+		return links, nil
+	}
+
+	generation := c.currentGeneration()
+	found, err := c.SwapRepository.GetOriginalURLs(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	for _, shortURL := range missing {
+		if link, ok := found[shortURL]; ok {
+			links[shortURL] = link
+			c.put(shortURL, &link, c.config.TTL, generation)
+		} else if c.config.NegativeTTL > 0 {
+			c.put(shortURL, nil, c.config.NegativeTTL, generation)
+		}
+	}
+	return links, nil
+}
+
+func (c *CachingRepository) CreateShortURL(ctx context.Context, link *model.Link) error {
+	// A remembered miss would hide the new link.
+	defer c.Invalidate(link.ShortURL)
+	return c.SwapRepository.CreateShortURL(ctx, link)
+}
+
+func (c *CachingRepository) CreateShortURLs(ctx context.Context, links []*model.Link) ([]int, error) {
+	shortURLs := make([]string, len(links))
+	for i, link := range links {
+		shortURLs[i] = link.ShortURL
+	}
+	defer c.Invalidate(shortURLs...)
+	return c.SwapRepository.CreateShortURLs(ctx, links)
+}
+
+func (c *CachingRepository) UpdateLink(ctx context.Context, link *model.Link) error {
+	defer c.Invalidate(link.ShortURL)
+	return c.SwapRepository.UpdateLink(ctx, link)
+}
+
+func (c *CachingRepository) DeleteLink(ctx context.Context, shortURL string, now time.Time) error {
+	defer c.Invalidate(shortURL)
+	return c.SwapRepository.DeleteLink(ctx, shortURL, now)
+}
+
+// DeleteExpired also drops cached links that expired by now, the sweep
+// does not report which codes it removed.
+func (c *CachingRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	deleted, err := c.SwapRepository.DeleteExpired(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	for shortURL, elem := range c.entries {
+		if link := elem.Value.(*cacheEntry).link; link != nil && link.Expired(now) {
+			c.order.Remove(elem)
+			delete(c.entries, shortURL)
+		}
+	}
+	return deleted, nil
+}
+
+// Invalidate evicts the given codes, so the next lookup reads through.
+func (c *CachingRepository) Invalidate(shortURLs ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	for _, shortURL := range shortURLs {
+		if elem, ok := c.entries[shortURL]; ok {
+			c.order.Remove(elem)
+			delete(c.entries, shortURL)
+		}
+	}
+}
+
+// Purge evicts every cached code.
+func (c *CachingRepository) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	clear(c.entries)
+	c.order.Init()
+}
+
+func (c *CachingRepository) Stats() CacheStats {
+	return CacheStats{
+		Hits:         c.hits.Load(),
+		NegativeHits: c.negativeHits.Load(),
+		Misses:       c.misses.Load(),
+		Evictions:    c.evictions.Load(),
+	}
+}
+
+// Len is the number of cached codes, remembered misses included.
+func (c *CachingRepository) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *CachingRepository) get(shortURL string) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[shortURL]
+	if !ok {
+		return cacheEntry{}, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if !c.now().Before(entry.expiresAt) {
+		c.order.Remove(elem)
+		delete(c.entries, shortURL)
+		return cacheEntry{}, false
+	}
+	c.order.MoveToFront(elem)
+	return *entry, true
+}
+
+func (c *CachingRepository) put(shortURL string, link *model.Link, ttl time.Duration, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+	entry := &cacheEntry{shortURL: shortURL, link: link, expiresAt: c.now().Add(ttl)}
+	if elem, ok := c.entries[shortURL]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[shortURL] = c.order.PushFront(entry)
+
+	if c.order.Len() > c.config.Size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).shortURL)
+		c.evictions.Add(1)
+	}
+}
+
+func (c *CachingRepository) currentGeneration() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
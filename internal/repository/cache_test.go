@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+	"urlShortener/internal/model"
+)
+
+// countingStorage считает обращения к хранилищу за ссылками
+type countingStorage struct {
+	*URLStorage
+	lookups int
+}
+
+func (s *countingStorage) GetOriginalURL(ctx context.Context, shortURL string) (*model.Link, error) {
+	s.lookups++
+	return s.URLStorage.GetOriginalURL(ctx, shortURL)
+}
+
+func (s *countingStorage) GetOriginalURLs(ctx context.Context, shortURLs []string) (map[string]model.Link, error) {
+	s.lookups += len(shortURLs)
+	return s.URLStorage.GetOriginalURLs(ctx, shortURLs)
+}
+
+func newCountingCache(t *testing.T, config CacheConfig, links ...model.Link) (*CachingRepository, *countingStorage, *time.Time) {
+	storage := &countingStorage{URLStorage: NewURLStorage(zap.NewNop())}
+	createLinks(t, storage, links...)
+	cache := NewCachingRepository(storage, config, zap.NewNop())
+	now := conformanceNow
+	cache.now = func() time.Time { return now }
+	return cache, storage, &now
+}
+
+func TestCachingRepositoryConformance(t *testing.T) {
+	runConformance(t, func(t *testing.T) SwapRepository {
+		return NewCachingRepository(NewURLStorage(zap.NewNop()), CacheConfig{Size: 16, TTL: time.Minute, NegativeTTL: time.Minute}, zap.NewNop())
+	})
+}
+
+func TestCachingRepositoryHits(t *testing.T) {
+	ctx := context.Background()
+	cache, storage, now := newCountingCache(t, CacheConfig{Size: 16, TTL: time.Minute}, conformanceLink(1, "b", "https://example.com/a"))
+
+	link, err := cache.GetOriginalURL(ctx, "b")
+	require.NoError(t, err)
+	// Изменение полученной копии не портит кеш
+	link.OriginalURL = "https://changed.example.com"
+
+	link, err = cache.GetOriginalURL(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/a", link.OriginalURL)
+	assert.Equal(t, 1, storage.lookups)
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 1}, cache.Stats())
+
+	// После TTL ссылка снова читается из хранилища
+	*now = now.Add(time.Minute)
+	_, err = cache.GetOriginalURL(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, 2, storage.lookups)
+}
+
+func TestCachingRepositoryNegative(t *testing.T) {
+	ctx := context.Background()
+	cache, storage, now := newCountingCache(t, CacheConfig{Size: 16, TTL: time.Minute, NegativeTTL: 10 * time.Second})
+
+	for range 3 {
+		_, err := cache.GetOriginalURL(ctx, "b")
+		assert.ErrorIs(t, err, ErrLinkNotFound)
+	}
+	assert.Equal(t, 1, storage.lookups)
+	assert.Equal(t, CacheStats{NegativeHits: 2, Misses: 1}, cache.Stats())
+
+	// Создание ссылки через кеш сбрасывает запомненный промах
+	link := conformanceLink(1, "b", "https://example.com/a")
+	require.NoError(t, cache.CreateShortURL(ctx, &link))
+	_, err := cache.GetOriginalURL(ctx, "b")
+	assert.NoError(t, err)
+
+	// Промахи живут NegativeTTL, а не TTL
+	_, err = cache.GetOriginalURL(ctx, "c")
+	assert.ErrorIs(t, err, ErrLinkNotFound)
+	*now = now.Add(10 * time.Second)
+	_, err = cache.GetOriginalURL(ctx, "c")
+	assert.ErrorIs(t, err, ErrLinkNotFound)
+	assert.Equal(t, 4, storage.lookups)
+}
+
+func TestCachingRepositoryInvalidation(t *testing.T) {
+	ctx := context.Background()
+	cache, _, _ := newCountingCache(t, CacheConfig{Size: 16, TTL: time.Minute}, conformanceLink(1, "b", "https://example.com/a"))
+
+	_, err := cache.GetOriginalURL(ctx, "b")
+	require.NoError(t, err)
+	require.NoError(t, cache.UpdateLink(ctx, &model.Link{ShortURL: "b", OriginalURL: "https://example.com/b"}))
+	link, err := cache.GetOriginalURL(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/b", link.OriginalURL)
+
+	require.NoError(t, cache.DeleteLink(ctx, "b", conformanceNow))
+	link, err = cache.GetOriginalURL(ctx, "b")
+	require.NoError(t, err)
+	assert.NotNil(t, link.DeletedAt)
+}
+
+func TestCachingRepositoryEviction(t *testing.T) {
+	ctx := context.Background()
+	cache, storage, _ := newCountingCache(t, CacheConfig{Size: 2, TTL: time.Minute},
+		conformanceLink(1, "b", "https://example.com/1"),
+		conformanceLink(2, "c", "https://example.com/2"),
+		conformanceLink(3, "d", "https://example.com/3"),
+	)
+
+	for _, code := range []string{"b", "c", "b", "d"} {
+		_, err := cache.GetOriginalURL(ctx, code)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, cache.Len())
+	assert.Equal(t, uint64(1), cache.Stats().Evictions)
+
+	// Вытеснен давно не использованный "c", а не "b"
+	_, err := cache.GetOriginalURL(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, 3, storage.lookups)
+	_, err = cache.GetOriginalURL(ctx, "c")
+	require.NoError(t, err)
+	assert.Equal(t, 4, storage.lookups)
+}
+
+func TestCachingRepositoryGetOriginalURLs(t *testing.T) {
+	ctx := context.Background()
+	cache, storage, _ := newCountingCache(t, CacheConfig{Size: 16, TTL: time.Minute, NegativeTTL: time.Minute},
+		conformanceLink(1, "b", "https://example.com/1"),
+		conformanceLink(2, "c", "https://example.com/2"),
+	)
+
+	_, err := cache.GetOriginalURL(ctx, "b")
+	require.NoError(t, err)
+
+	links, err := cache.GetOriginalURLs(ctx, []string{"b", "c", "missing"})
+	require.NoError(t, err)
+	assert.Len(t, links, 2)
+	assert.Equal(t, 3, storage.lookups)
+
+	// Повторный запрос целиком обслуживается кешем, включая промах
+	links, err = cache.GetOriginalURLs(ctx, []string{"b", "c", "missing"})
+	require.NoError(t, err)
+	assert.Len(t, links, 2)
+	assert.Equal(t, 3, storage.lookups)
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"sort"
+	"sync"
+	"time"
+	"urlShortener/internal/model"
+)
+
+// ClickStorage keeps the most recent clicks in a fixed-size ring, the
+// oldest events are overwritten once it is full.
+type ClickStorage struct {
+	mu     sync.Mutex
+	ring   []model.Click
+	next   int
+	full   bool
+	logger *zap.Logger
+}
+
+func NewClickStorage(size int, logger *zap.Logger) *ClickStorage {
+	if size <= 0 {
+		size = 1
+	}
+	return &ClickStorage{
+		ring:   make([]model.Click, size),
+		logger: logger,
+	}
+}
+
+func (s *ClickStorage) SaveClicks(ctx context.Context, clicks []model.Click) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, click := range clicks {
+		s.ring[s.next] = click
+		s.next = (s.next + 1) % len(s.ring)
+		if s.next == 0 {
+			s.full = true
+		}
+	}
+	return nil
+}
+
+func (s *ClickStorage) GetStats(ctx context.Context, shortURL string) (*model.Stats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := s.next
+	if s.full {
+		n = len(s.ring)
+	}
+
+	perDay := make(map[string]int64)
+	stats := &model.Stats{ShortURL: shortURL, Daily: []model.DailyClicks{}}
+	for _, click := range s.ring[:n] {
+		if click.ShortURL != shortURL {
+			continue
+		}
+		perDay[click.ClickedAt.UTC().Format(time.DateOnly)]++
+		stats.Total++
+	}
+
+	for day, count := range perDay {
+		stats.Daily = append(stats.Daily, model.DailyClicks{Date: day, Count: count})
+	}
+	sort.Slice(stats.Daily, func(i, j int) bool { return stats.Daily[i].Date < stats.Daily[j].Date })
+	return stats, nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+	"urlShortener/internal/initialize"
+	"urlShortener/internal/model"
+)
+
+type ClickRepository interface {
+	SaveClicks(ctx context.Context, clicks []model.Click) error
+	GetStats(ctx context.Context, shortURL string) (*model.Stats, error)
+}
+
+type PgClickRepository struct {
+	pool   PgxIface
+	logger *zap.Logger
+}
+
+func NewClickRepository(dbInstance *initialize.DB, logger *zap.Logger) *PgClickRepository {
+	return &PgClickRepository{
+		pool:   dbInstance.Pool,
+		logger: logger,
+	}
+}
+
+// SaveClicks inserts clicks bulkInsertRows at a time. Each chunk is saved on
+// its own, a failed one does not take back those before it.
+func (r *PgClickRepository) SaveClicks(ctx context.Context, clicks []model.Click) error {
+	for start := 0; start < len(clicks); start += bulkInsertRows {
+		chunk := clicks[start:min(start+bulkInsertRows, len(clicks))]
+
+		var sb strings.Builder
+		sb.WriteString("INSERT INTO clicks (short_url, clicked_at, referrer, user_agent, ip) VALUES ")
+		args := make([]any, 0, len(chunk)*5)
+		for i, click := range chunk {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			n := i * 5
+			fmt.Fprintf(&sb, "($%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5)
+			args = append(args, click.ShortURL, click.ClickedAt, click.Referrer, click.UserAgent, click.IP)
+		}
+
+		if _, err := r.pool.Exec(ctx, sb.String(), args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *PgClickRepository) GetStats(ctx context.Context, shortURL string) (*model.Stats, error) {
+	rows, err := r.pool.Query(ctx,
+		"SELECT (clicked_at AT TIME ZONE 'UTC')::date AS day, COUNT(*) FROM clicks WHERE short_url = $1 GROUP BY day ORDER BY day", shortURL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := &model.Stats{ShortURL: shortURL, Daily: []model.DailyClicks{}}
+	for rows.Next() {
+		var day time.Time
+		var count int64
+		if err := rows.Scan(&day, &count); err != nil {
+			return nil, err
+		}
+		stats.Total += count
+		stats.Daily = append(stats.Daily, model.DailyClicks{Date: day.Format(time.DateOnly), Count: count})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
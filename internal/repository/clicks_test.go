@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+	"urlShortener/internal/model"
+)
+
+func TestSaveClicks(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock pool: %v", err)
+	}
+	defer mockPool.Close()
+
+	repo := PgClickRepository{pool: mockPool}
+	now := time.Now()
+	clicks := []model.Click{
+		{ShortURL: "abc123", ClickedAt: now, Referrer: "https://ref.example", UserAgent: "curl", IP: "10.0.0.0"},
+		{ShortURL: "abc123", ClickedAt: now, IP: "10.0.1.0"},
+	}
+
+	// Случай, когда клики записаны одним запросом
+	mockPool.ExpectExec("INSERT INTO clicks").
+		WithArgs("abc123", now, "https://ref.example", "curl", "10.0.0.0", "abc123", now, "", "", "10.0.1.0").
+		WillReturnResult(pgxmock.NewResult("INSERT", 2))
+
+	err = repo.SaveClicks(context.Background(), clicks)
+	assert.NoError(t, err)
+
+	// Случай, когда большой пакет разбивается на запросы по bulkInsertRows строк
+	many := make([]model.Click, bulkInsertRows+1)
+	for i := range many {
+		many[i] = model.Click{ShortURL: "abc123", ClickedAt: now}
+	}
+	args := make([]any, 0, bulkInsertRows*5)
+	for range bulkInsertRows {
+		args = append(args, "abc123", now, "", "", "")
+	}
+	mockPool.ExpectExec("INSERT INTO clicks").
+		WithArgs(args...).
+		WillReturnResult(pgxmock.NewResult("INSERT", bulkInsertRows))
+	mockPool.ExpectExec("INSERT INTO clicks").
+		WithArgs("abc123", now, "", "", "").
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	err = repo.SaveClicks(context.Background(), many)
+	assert.NoError(t, err)
+
+	// Случай, когда ошибка при выполнении запроса
+	mockPool.ExpectExec("INSERT INTO clicks").
+		WillReturnError(fmt.Errorf("database error"))
+
+	err = repo.SaveClicks(context.Background(), clicks)
+	assert.Error(t, err)
+}
+
+func TestGetStats(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock pool: %v", err)
+	}
+	defer mockPool.Close()
+
+	repo := PgClickRepository{pool: mockPool}
+
+	// Случай, когда статистика по дням получена
+	mockPool.ExpectQuery("SELECT .+ FROM clicks WHERE short_url").
+		WithArgs("abc123").
+		WillReturnRows(pgxmock.NewRows([]string{"day", "count"}).
+			AddRow(time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC), int64(1)).
+			AddRow(time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), int64(2)))
+
+	stats, err := repo.GetStats(context.Background(), "abc123")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), stats.Total)
+	assert.Equal(t, []model.DailyClicks{{Date: "2026-10-17", Count: 1}, {Date: "2026-10-18", Count: 2}}, stats.Daily)
+
+	// Случай, когда ошибка при выполнении запроса
+	mockPool.ExpectQuery("SELECT .+ FROM clicks WHERE short_url").
+		WithArgs("abc123").
+		WillReturnError(fmt.Errorf("database error"))
+
+	_, err = repo.GetStats(context.Background(), "abc123")
+	assert.Error(t, err)
+}
+
+func TestClickStorageRing(t *testing.T) {
+	storage := NewClickStorage(2, nil)
+	day := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	err := storage.SaveClicks(context.Background(), []model.Click{
+		{ShortURL: "abc123", ClickedAt: day.AddDate(0, 0, -1)},
+		{ShortURL: "abc123", ClickedAt: day},
+		{ShortURL: "abc123", ClickedAt: day},
+	})
+	assert.NoError(t, err)
+
+	// Самый старый клик вытеснен из кольца
+	stats, err := storage.GetStats(context.Background(), "abc123")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), stats.Total)
+	assert.Equal(t, []model.DailyClicks{{Date: "2026-10-18", Count: 2}}, stats.Daily)
+}
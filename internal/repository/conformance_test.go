@@ -0,0 +1,448 @@
+package repository
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+	"urlShortener/internal/model"
+)
+
+// conformanceCase is a behavior every SwapRepository implementation must share.
+// run works against a fresh, empty repository. pg scripts the queries the
+// Postgres repository sends for the same calls; cases that depend on real
+// concurrency leave it nil and are skipped there.
+type conformanceCase struct {
+	name string
+	pg   func(mock pgxmock.PgxPoolIface)
+	run  func(t *testing.T, repo SwapRepository)
+}
+
+var (
+	conformanceNow   = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	conformanceLater = conformanceNow.Add(time.Hour)
+	conformanceEarly = conformanceNow.Add(-time.Hour)
+)
+
+func conformanceLink(id int, shortURL string, originalURL string) model.Link {
+	return model.Link{ID: id, ShortURL: shortURL, OriginalURL: originalURL, CreatedAt: conformanceNow}
+}
+
+func insertArgs(link model.Link) []any {
+	return []any{link.ID, link.ShortURL, link.OriginalURL, link.RedirectType, link.ExpiresAt, link.Owner, link.CreatedAt}
+}
+
+func expectInsert(mock pgxmock.PgxPoolIface, links ...model.Link) {
+	for _, link := range links {
+		mock.ExpectExec("INSERT INTO links").
+			WithArgs(insertArgs(link)...).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	}
+}
+
+func createLinks(t *testing.T, repo SwapRepository, links ...model.Link) {
+	t.Helper()
+	for _, link := range links {
+		require.NoError(t, repo.CreateShortURL(context.Background(), &link))
+	}
+}
+
+// sameLink compares links regardless of the time zone a backend reads timestamps back in.
+func sameLink(t *testing.T, want model.Link, got model.Link) {
+	t.Helper()
+	utc := func(link model.Link) model.Link {
+		link.CreatedAt = link.CreatedAt.UTC()
+		if link.ExpiresAt != nil {
+			expiresAt := link.ExpiresAt.UTC()
+			link.ExpiresAt = &expiresAt
+		}
+		if link.DeletedAt != nil {
+			deletedAt := link.DeletedAt.UTC()
+			link.DeletedAt = &deletedAt
+		}
+		return link
+	}
+	assert.Equal(t, utc(want), utc(got))
+}
+
+var conformanceCases = []conformanceCase{
+	{
+		name: "create and get",
+		pg: func(mock pgxmock.PgxPoolIface) {
+			link := conformanceLink(1, "b", "https://example.com/a")
+			link.RedirectType, link.ExpiresAt, link.Owner = 301, &conformanceLater, "marketing"
+			expectInsert(mock, link)
+			mock.ExpectQuery(`SELECT (.+) FROM links WHERE short_url = \$1`).WithArgs("b").WillReturnRows(linkRows(link))
+			mock.ExpectQuery("SELECT EXISTS").WithArgs("b").WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+			mock.ExpectQuery("short_url = ANY").WithArgs([]string{"b", "missing"}).WillReturnRows(linkRows(link))
+		},
+		run: func(t *testing.T, repo SwapRepository) {
+			ctx := context.Background()
+			link := conformanceLink(1, "b", "https://example.com/a")
+			link.RedirectType, link.ExpiresAt, link.Owner = 301, &conformanceLater, "marketing"
+			createLinks(t, repo, link)
+
+			got, err := repo.GetOriginalURL(ctx, "b")
+			require.NoError(t, err)
+			sameLink(t, link, *got)
+
+			exists, err := repo.ShortURLExists(ctx, "b")
+			require.NoError(t, err)
+			assert.True(t, exists)
+
+			// Отсутствующие коды просто не попадают в результат
+			links, err := repo.GetOriginalURLs(ctx, []string{"b", "missing"})
+			require.NoError(t, err)
+			require.Len(t, links, 1)
+			sameLink(t, link, links["b"])
+		},
+	},
+	{
+		name: "missing link",
+		pg: func(mock pgxmock.PgxPoolIface) {
+			mock.ExpectQuery("SELECT (.+) FROM links WHERE short_url").WithArgs("missing").WillReturnError(pgx.ErrNoRows)
+			mock.ExpectQuery("SELECT EXISTS").WithArgs("missing").WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(false))
+			mock.ExpectQuery("SELECT short_url FROM links").WithArgs("https://example.com/a", "").WillReturnError(pgx.ErrNoRows)
+			mock.ExpectExec("UPDATE links SET original_url").
+				WithArgs("missing", "https://example.com/b", 0, (*time.Time)(nil)).
+				WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+			mock.ExpectExec("UPDATE links SET deleted_at").WithArgs("missing", conformanceNow).WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		},
+		run: func(t *testing.T, repo SwapRepository) {
+			ctx := context.Background()
+
+			_, err := repo.GetOriginalURL(ctx, "missing")
+			assert.ErrorIs(t, err, ErrLinkNotFound)
+
+			exists, err := repo.ShortURLExists(ctx, "missing")
+			require.NoError(t, err)
+			assert.False(t, exists)
+
+			_, err = repo.CheckDublicate(ctx, "https://example.com/a", "")
+			assert.ErrorIs(t, err, ErrLinkNotFound)
+
+			assert.ErrorIs(t, repo.UpdateLink(ctx, &model.Link{ShortURL: "missing", OriginalURL: "https://example.com/b"}), ErrLinkNotFound)
+			assert.ErrorIs(t, repo.DeleteLink(ctx, "missing", conformanceNow), ErrLinkNotFound)
+		},
+	},
+	{
+		name: "duplicate short url",
+		pg: func(mock pgxmock.PgxPoolIface) {
+			expectInsert(mock, conformanceLink(1, "b", "https://example.com/a"))
+			mock.ExpectExec("INSERT INTO links").
+				WithArgs(insertArgs(conformanceLink(2, "b", "https://example.com/b"))...).
+				WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "links_short_url_idx"})
+			mock.ExpectQuery("SELECT (.+) FROM links WHERE short_url").WithArgs("b").
+				WillReturnRows(linkRows(conformanceLink(1, "b", "https://example.com/a")))
+		},
+		run: func(t *testing.T, repo SwapRepository) {
+			ctx := context.Background()
+			createLinks(t, repo, conformanceLink(1, "b", "https://example.com/a"))
+
+			second := conformanceLink(2, "b", "https://example.com/b")
+			assert.ErrorIs(t, repo.CreateShortURL(ctx, &second), ErrShortURLExists)
+
+			// Первая ссылка не затирается
+			got, err := repo.GetOriginalURL(ctx, "b")
+			require.NoError(t, err)
+			assert.Equal(t, "https://example.com/a", got.OriginalURL)
+		},
+	},
+	{
+		name: "check duplicate picks lowest id",
+		pg: func(mock pgxmock.PgxPoolIface) {
+			expiring := conformanceLink(1, "b", "https://example.com/a")
+			expiring.ExpiresAt = &conformanceLater
+			other := conformanceLink(4, "e", "https://example.com/a")
+			other.Owner = "other"
+			expectInsert(mock, conformanceLink(3, "d", "https://example.com/a"), conformanceLink(2, "c", "https://example.com/a"), other, expiring)
+
+			shortURL := func(code string) *pgxmock.Rows { return pgxmock.NewRows([]string{"short_url"}).AddRow(code) }
+			mock.ExpectQuery("SELECT short_url FROM links").WithArgs("https://example.com/a", "").WillReturnRows(shortURL("c"))
+			mock.ExpectExec("UPDATE links SET deleted_at").WithArgs("c", conformanceNow).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+			mock.ExpectQuery("SELECT short_url FROM links").WithArgs("https://example.com/a", "").WillReturnRows(shortURL("d"))
+			mock.ExpectQuery("SELECT short_url FROM links").WithArgs("https://example.com/a", "other").WillReturnRows(shortURL("e"))
+		},
+		run: func(t *testing.T, repo SwapRepository) {
+			ctx := context.Background()
+			expiring := conformanceLink(1, "b", "https://example.com/a")
+			expiring.ExpiresAt = &conformanceLater
+			other := conformanceLink(4, "e", "https://example.com/a")
+			other.Owner = "other"
+			// Порядок вставки не совпадает с порядком ID
+			createLinks(t, repo, conformanceLink(3, "d", "https://example.com/a"), conformanceLink(2, "c", "https://example.com/a"), other, expiring)
+
+			shortURL, err := repo.CheckDublicate(ctx, "https://example.com/a", "")
+			require.NoError(t, err)
+			assert.Equal(t, "c", shortURL)
+
+			require.NoError(t, repo.DeleteLink(ctx, "c", conformanceNow))
+			shortURL, err = repo.CheckDublicate(ctx, "https://example.com/a", "")
+			require.NoError(t, err)
+			assert.Equal(t, "d", shortURL)
+
+			shortURL, err = repo.CheckDublicate(ctx, "https://example.com/a", "other")
+			require.NoError(t, err)
+			assert.Equal(t, "e", shortURL)
+		},
+	},
+	{
+		name: "next ids",
+		pg: func(mock pgxmock.PgxPoolIface) {
+			mock.ExpectQuery(`SELECT nextval\('links_id_seq'\)$`).WillReturnRows(pgxmock.NewRows([]string{"nextval"}).AddRow(1))
+			mock.ExpectQuery("generate_series").WithArgs(3).
+				WillReturnRows(pgxmock.NewRows([]string{"nextval"}).AddRow(2).AddRow(3).AddRow(4))
+			mock.ExpectQuery(`SELECT nextval\('links_id_seq'\)$`).WillReturnRows(pgxmock.NewRows([]string{"nextval"}).AddRow(5))
+		},
+		run: func(t *testing.T, repo SwapRepository) {
+			ctx := context.Background()
+
+			first, err := repo.GetNextID(ctx)
+			require.NoError(t, err)
+			ids, err := repo.GetNextIDs(ctx, 3)
+			require.NoError(t, err)
+			assert.Equal(t, []int{first + 1, first + 2, first + 3}, ids)
+			next, err := repo.GetNextID(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, first+4, next)
+		},
+	},
+	{
+		name: "concurrent next ids are unique",
+		run: func(t *testing.T, repo SwapRepository) {
+			ctx := context.Background()
+			var mu sync.Mutex
+			var wg sync.WaitGroup
+			seen := make(map[int]int)
+			for range 8 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for range 25 {
+						id, err := repo.GetNextID(ctx)
+						assert.NoError(t, err)
+						ids, err := repo.GetNextIDs(ctx, 2)
+						assert.NoError(t, err)
+
+						mu.Lock()
+						for _, id := range append(ids, id) {
+							seen[id]++
+						}
+						mu.Unlock()
+					}
+				}()
+			}
+			wg.Wait()
+
+			assert.Len(t, seen, 8*25*3)
+		},
+	},
+	{
+		name: "concurrent creates of one code",
+		run: func(t *testing.T, repo SwapRepository) {
+			ctx := context.Background()
+			var wg sync.WaitGroup
+			errs := make([]error, 16)
+			for i := range errs {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					link := conformanceLink(i+1, "b", "https://example.com/a")
+					errs[i] = repo.CreateShortURL(ctx, &link)
+				}()
+			}
+			wg.Wait()
+
+			// Ровно один запрос занимает код, остальные получают ErrShortURLExists
+			created := 0
+			for _, err := range errs {
+				if err == nil {
+					created++
+					continue
+				}
+				assert.ErrorIs(t, err, ErrShortURLExists)
+			}
+			assert.Equal(t, 1, created)
+		},
+	},
+	{
+		name: "bulk create skips taken codes",
+		pg: func(mock pgxmock.PgxPoolIface) {
+			expectInsert(mock, conformanceLink(1, "b", "https://example.com/a"))
+			var args []any
+			for _, link := range []model.Link{conformanceLink(2, "c", "https://example.com/c"), conformanceLink(3, "b", "https://example.com/b"), conformanceLink(4, "d", "https://example.com/d")} {
+				args = append(args, insertArgs(link)...)
+			}
+			mock.ExpectBegin()
+			mock.ExpectQuery("INSERT INTO links").WithArgs(args...).
+				WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(2).AddRow(4))
+			mock.ExpectCommit()
+			mock.ExpectQuery("SELECT (.+) FROM links WHERE short_url").WithArgs("b").
+				WillReturnRows(linkRows(conformanceLink(1, "b", "https://example.com/a")))
+		},
+		run: func(t *testing.T, repo SwapRepository) {
+			ctx := context.Background()
+			createLinks(t, repo, conformanceLink(1, "b", "https://example.com/a"))
+
+			c, b, d := conformanceLink(2, "c", "https://example.com/c"), conformanceLink(3, "b", "https://example.com/b"), conformanceLink(4, "d", "https://example.com/d")
+			taken, err := repo.CreateShortURLs(ctx, []*model.Link{&c, &b, &d})
+			require.NoError(t, err)
+			assert.Equal(t, []int{3}, taken)
+
+			got, err := repo.GetOriginalURL(ctx, "b")
+			require.NoError(t, err)
+			assert.Equal(t, "https://example.com/a", got.OriginalURL)
+		},
+	},
+	{
+		name: "update and delete",
+		pg: func(mock pgxmock.PgxPoolIface) {
+			link := conformanceLink(1, "b", "https://example.com/a")
+			expectInsert(mock, link)
+			mock.ExpectExec("UPDATE links SET original_url").
+				WithArgs("b", "https://example.com/b", 301, &conformanceLater).
+				WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+			link.OriginalURL, link.RedirectType, link.ExpiresAt = "https://example.com/b", 301, &conformanceLater
+			mock.ExpectQuery("SELECT (.+) FROM links WHERE short_url").WithArgs("b").WillReturnRows(linkRows(link))
+			mock.ExpectExec("UPDATE links SET deleted_at").WithArgs("b", conformanceNow).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+			link.DeletedAt = &conformanceNow
+			mock.ExpectQuery("SELECT (.+) FROM links WHERE short_url").WithArgs("b").WillReturnRows(linkRows(link))
+			mock.ExpectQuery("SELECT EXISTS").WithArgs("b").WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+			mock.ExpectExec("UPDATE links SET original_url").
+				WithArgs("b", "https://example.com/c", 0, (*time.Time)(nil)).
+				WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+			mock.ExpectExec("UPDATE links SET deleted_at").WithArgs("b", conformanceNow).WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		},
+		run: func(t *testing.T, repo SwapRepository) {
+			ctx := context.Background()
+			link := conformanceLink(1, "b", "https://example.com/a")
+			createLinks(t, repo, link)
+
+			require.NoError(t, repo.UpdateLink(ctx, &model.Link{ShortURL: "b", OriginalURL: "https://example.com/b", RedirectType: 301, ExpiresAt: &conformanceLater}))
+			link.OriginalURL, link.RedirectType, link.ExpiresAt = "https://example.com/b", 301, &conformanceLater
+			got, err := repo.GetOriginalURL(ctx, "b")
+			require.NoError(t, err)
+			sameLink(t, link, *got)
+
+			// Удалённая ссылка остаётся надгробием и держит код
+			require.NoError(t, repo.DeleteLink(ctx, "b", conformanceNow))
+			link.DeletedAt = &conformanceNow
+			got, err = repo.GetOriginalURL(ctx, "b")
+			require.NoError(t, err)
+			sameLink(t, link, *got)
+			exists, err := repo.ShortURLExists(ctx, "b")
+			require.NoError(t, err)
+			assert.True(t, exists)
+
+			assert.ErrorIs(t, repo.UpdateLink(ctx, &model.Link{ShortURL: "b", OriginalURL: "https://example.com/c"}), ErrLinkNotFound)
+			assert.ErrorIs(t, repo.DeleteLink(ctx, "b", conformanceNow), ErrLinkNotFound)
+		},
+	},
+	{
+		name: "delete expired",
+		pg: func(mock pgxmock.PgxPoolIface) {
+			expired := conformanceLink(1, "b", "https://example.com/a")
+			expired.ExpiresAt = &conformanceEarly
+			expectInsert(mock, expired, conformanceLink(2, "c", "https://example.com/a"))
+			mock.ExpectExec("DELETE FROM links WHERE expires_at").WithArgs(conformanceNow).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+			mock.ExpectQuery("SELECT (.+) FROM links WHERE short_url").WithArgs("b").WillReturnError(pgx.ErrNoRows)
+			mock.ExpectQuery("SELECT (.+) FROM links WHERE short_url").WithArgs("c").
+				WillReturnRows(linkRows(conformanceLink(2, "c", "https://example.com/a")))
+		},
+		run: func(t *testing.T, repo SwapRepository) {
+			ctx := context.Background()
+			expired := conformanceLink(1, "b", "https://example.com/a")
+			expired.ExpiresAt = &conformanceEarly
+			createLinks(t, repo, expired, conformanceLink(2, "c", "https://example.com/a"))
+
+			deleted, err := repo.DeleteExpired(ctx, conformanceNow)
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), deleted)
+
+			_, err = repo.GetOriginalURL(ctx, "b")
+			assert.ErrorIs(t, err, ErrLinkNotFound)
+			_, err = repo.GetOriginalURL(ctx, "c")
+			assert.NoError(t, err)
+		},
+	},
+	{
+		name: "delete expired keeps tombstones",
+		pg: func(mock pgxmock.PgxPoolIface) {
+			link := conformanceLink(1, "b", "https://example.com/a")
+			link.ExpiresAt = &conformanceEarly
+			expectInsert(mock, link)
+			mock.ExpectExec("UPDATE links SET deleted_at").WithArgs("b", conformanceEarly).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+			mock.ExpectExec(`DELETE FROM links WHERE expires_at <= \$1 AND deleted_at IS NULL`).WithArgs(conformanceNow).WillReturnResult(pgxmock.NewResult("DELETE", 0))
+			mock.ExpectQuery("SELECT EXISTS").WithArgs("b").WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+		},
+		run: func(t *testing.T, repo SwapRepository) {
+			ctx := context.Background()
+			link := conformanceLink(1, "b", "https://example.com/a")
+			link.ExpiresAt = &conformanceEarly
+			createLinks(t, repo, link)
+			require.NoError(t, repo.DeleteLink(ctx, "b", conformanceEarly))
+
+			// Просроченное надгробие не удаляется, код по-прежнему занят
+			deleted, err := repo.DeleteExpired(ctx, conformanceNow)
+			require.NoError(t, err)
+			assert.Equal(t, int64(0), deleted)
+			exists, err := repo.ShortURLExists(ctx, "b")
+			require.NoError(t, err)
+			assert.True(t, exists)
+		},
+	},
+}
+
+// runConformance runs every conformance case against a fresh repository from newRepo.
+func runConformance(t *testing.T, newRepo func(t *testing.T) SwapRepository) {
+	for _, c := range conformanceCases {
+		t.Run(c.name, func(t *testing.T) {
+			c.run(t, newRepo(t))
+		})
+	}
+}
+
+func TestURLStorageConformance(t *testing.T) {
+	runConformance(t, func(t *testing.T) SwapRepository {
+		return NewURLStorage(zap.NewNop())
+	})
+}
+
+func TestFileStorageConformance(t *testing.T) {
+	runConformance(t, func(t *testing.T) SwapRepository {
+		storage, err := NewFileStorage(filepath.Join(t.TempDir(), "links.log"), zap.NewNop())
+		require.NoError(t, err)
+		t.Cleanup(func() { storage.Close() })
+		return storage
+	})
+}
+
+func TestSQLiteRepositoryConformance(t *testing.T) {
+	runConformance(t, func(t *testing.T) SwapRepository {
+		return newSQLiteRepository(t)
+	})
+}
+
+func TestShortenerRepositoryConformance(t *testing.T) {
+	for _, c := range conformanceCases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.pg == nil {
+				t.Skip("needs a real Postgres")
+			}
+			mockPool, err := pgxmock.NewPool()
+			require.NoError(t, err)
+			defer mockPool.Close()
+
+			c.pg(mockPool)
+			c.run(t, &ShortenerRepository{pool: mockPool, logger: zap.NewNop()})
+			assert.NoError(t, mockPool.ExpectationsWereMet())
+		})
+	}
+}
@@ -2,4 +2,10 @@ package repository
 
 import "errors"
 
-var ErrLinkNotFound = errors.New("link not found")
+var (
+	ErrLinkNotFound   = errors.New("link not found")
+	ErrShortURLExists = errors.New("short URL already exists")
+	ErrKeyNotFound    = errors.New("API key not found")
+	ErrKeyExists      = errors.New("API key already exists")
+	ErrKeyNameTaken   = errors.New("API key name is already taken")
+)
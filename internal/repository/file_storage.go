@@ -0,0 +1,409 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"urlShortener/internal/model"
+)
+
+const (
+	opCreate = "create"
+	opUpdate = "update"
+	opDelete = "delete"
+	opExpire = "expire"
+	// opCounter carries the ID high-water mark through compaction, links
+	// removed by the sweeper no longer hold it up and IDs are never reused.
+	opCounter = "counter"
+)
+
+// fileLink is model.Link as it is written to the log, tombstones included.
+type fileLink struct {
+	ID           int        `json:"id"`
+	ShortURL     string     `json:"short_url"`
+	OriginalURL  string     `json:"original_url"`
+	RedirectType int        `json:"redirect_type,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+	Owner        string     `json:"owner,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+type logRecord struct {
+	Op       string     `json:"op"`
+	Link     *fileLink  `json:"link,omitempty"`
+	ShortURL string     `json:"short_url,omitempty"`
+	At       *time.Time `json:"at,omitempty"`
+	LastID   int64      `json:"last_id,omitempty"`
+}
+
+// FileStorage is URLStorage made durable by an append-only log. Every
+// change is appended to the log as one JSON line and only then applied in
+// memory, so a failed append leaves no link behind that a restart would
+// lose; on start the log is replayed. Compact rewrites the log as one
+// record per link, tombstones included, and the ID counter, so it does
+// not grow without bound.
+//
+// Unless SyncWrites is on, appends are not fsynced: a crash of the process
+// loses nothing but a crash of the machine may lose the last writes. A
+// torn last line is dropped on replay.
+//
+// logMu serializes all writes, so the checks made before an append still
+// hold when the change is applied.
+type FileStorage struct {
+	*URLStorage
+	logMu    sync.Mutex
+	path     string
+	file     *os.File
+	sync     bool
+	appended int
+	logger   *zap.Logger
+}
+
+func NewFileStorage(path string, logger *zap.Logger) (*FileStorage, error) {
+	s := &FileStorage{
+		URLStorage: NewURLStorage(logger),
+		path:       path,
+		logger:     logger,
+	}
+	if err := s.replay(); err != nil {
+		return nil, err
+	}
+	if err := s.Compact(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SyncWrites makes every append wait for fsync, so an acknowledged write
+// survives a crash of the machine too, at the cost of write latency.
+func (s *FileStorage) SyncWrites(sync bool) {
+	s.logMu.Lock()
+	defer s.logMu.Unlock()
+	s.sync = sync
+}
+
+func (s *FileStorage) CreateShortURL(ctx context.Context, link *model.Link) error {
+	s.logMu.Lock()
+	defer s.logMu.Unlock()
+
+	if exists, _ := s.URLStorage.ShortURLExists(ctx, link.ShortURL); exists {
+		return ErrShortURLExists
+	}
+	if err := s.append(logRecord{Op: opCreate, Link: toFileLink(*link)}); err != nil {
+		return err
+	}
+	return s.URLStorage.CreateShortURL(ctx, link)
+}
+
+func (s *FileStorage) CreateShortURLs(ctx context.Context, links []*model.Link) ([]int, error) {
+	s.logMu.Lock()
+	defer s.logMu.Unlock()
+
+	var taken []int
+	fresh := make([]*model.Link, 0, len(links))
+	seen := make(map[string]struct{}, len(links))
+	for _, link := range links {
+		_, repeated := seen[link.ShortURL]
+		if exists, _ := s.URLStorage.ShortURLExists(ctx, link.ShortURL); exists || repeated {
+			taken = append(taken, link.ID)
+			continue
+		}
+		seen[link.ShortURL] = struct{}{}
+		fresh = append(fresh, link)
+	}
+
+	records := make([]logRecord, 0, len(fresh))
+	for _, link := range fresh {
+		records = append(records, logRecord{Op: opCreate, Link: toFileLink(*link)})
+	}
+	if err := s.append(records...); err != nil {
+		return nil, err
+	}
+	if _, err := s.URLStorage.CreateShortURLs(ctx, fresh); err != nil {
+		return nil, err
+	}
+	return taken, nil
+}
+
+func (s *FileStorage) UpdateLink(ctx context.Context, link *model.Link) error {
+	s.logMu.Lock()
+	defer s.logMu.Unlock()
+
+	if !s.URLStorage.live(link.ShortURL) {
+		return ErrLinkNotFound
+	}
+	if err := s.append(logRecord{Op: opUpdate, Link: toFileLink(*link)}); err != nil {
+		return err
+	}
+	return s.URLStorage.UpdateLink(ctx, link)
+}
+
+func (s *FileStorage) DeleteLink(ctx context.Context, shortURL string, now time.Time) error {
+	s.logMu.Lock()
+	defer s.logMu.Unlock()
+
+	if !s.URLStorage.live(shortURL) {
+		return ErrLinkNotFound
+	}
+	if err := s.append(logRecord{Op: opDelete, ShortURL: shortURL, At: &now}); err != nil {
+		return err
+	}
+	return s.URLStorage.DeleteLink(ctx, shortURL, now)
+}
+
+func (s *FileStorage) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	s.logMu.Lock()
+	defer s.logMu.Unlock()
+
+	if !s.URLStorage.hasExpired(now) {
+		return 0, nil
+	}
+	if err := s.append(logRecord{Op: opExpire, At: &now}); err != nil {
+		return 0, err
+	}
+	return s.URLStorage.DeleteExpired(ctx, now)
+}
+
+// Compact replaces the log with a snapshot of the current links. The
+// snapshot is written next to the log and renamed over it, so a crash
+// leaves either the old or the new log.
+func (s *FileStorage) Compact() error {
+	s.logMu.Lock()
+	defer s.logMu.Unlock()
+
+	// The new log is opened for appending up front: its handle follows the
+	// file through the rename, and the old log stays in use until then.
+	tmpPath := s.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	discard := func(err error) error {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+
+	writer := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(writer)
+	links := s.URLStorage.snapshot()
+	if err := encoder.Encode(logRecord{Op: opCounter, LastID: s.URLStorage.lastID.Load()}); err != nil {
+		return discard(err)
+	}
+	for _, link := range links {
+		if err := encoder.Encode(logRecord{Op: opCreate, Link: toFileLink(link)}); err != nil {
+			return discard(err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return discard(err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return discard(err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return discard(err)
+	}
+	syncDir(filepath.Dir(s.path))
+
+	if s.file != nil {
+		s.file.Close()
+	}
+	s.file = tmp
+	s.appended = 0
+	s.logger.Info("storage log compacted", zap.String("path", s.path), zap.Int("links", len(links)))
+	return nil
+}
+
+// RunCompaction compacts the log every interval if anything was appended
+// since the last compaction, until ctx is done.
+func (s *FileStorage) RunCompaction(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.logMu.Lock()
+			appended := s.appended
+			s.logMu.Unlock()
+			if appended == 0 {
+				continue
+			}
+			if err := s.Compact(); err != nil {
+				s.logger.Error("error compacting storage log", zap.String("path", s.path), zap.Error(err))
+			}
+		}
+	}
+}
+
+func (s *FileStorage) Close() error {
+	s.logMu.Lock()
+	defer s.logMu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Sync()
+	if closeErr := s.file.Close(); err == nil {
+		err = closeErr
+	}
+	s.file = nil
+	return err
+}
+
+func (s *FileStorage) append(records ...logRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	if s.file == nil {
+		return errors.New("storage log is closed")
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	offset, err := s.file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if _, err := s.file.Write(buf.Bytes()); err != nil {
+		s.logger.Error("error appending to storage log", zap.String("path", s.path), zap.Error(err))
+		// Cut a partial write off so later records do not follow a torn line.
+		s.file.Truncate(offset)
+		return err
+	}
+	if s.sync {
+		if err := s.file.Sync(); err != nil {
+			s.logger.Error("error syncing storage log", zap.String("path", s.path), zap.Error(err))
+			s.file.Truncate(offset)
+			return err
+		}
+	}
+	s.appended += len(records)
+	return nil
+}
+
+// replay applies the log to the in-memory storage. A missing log is an
+// empty one; a last line without its newline is a torn write and is cut off.
+func (s *FileStorage) replay() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// Replayed changes are not news, keep them out of the log output.
+	s.URLStorage.logger = zap.NewNop()
+	defer func() { s.URLStorage.logger = s.logger }()
+
+	ctx := context.Background()
+	reader := bufio.NewReader(file)
+	var offset int64
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(data) > 0 {
+				s.logger.Warn("dropping torn record at the end of storage log", zap.String("path", s.path), zap.Int("line", line))
+				return file.Truncate(offset)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var record logRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return fmt.Errorf("%s:%d: %w", s.path, line, err)
+		}
+		if err := s.apply(ctx, record); err != nil {
+			return fmt.Errorf("%s:%d: %w", s.path, line, err)
+		}
+		offset += int64(len(data))
+	}
+}
+
+func (s *FileStorage) apply(ctx context.Context, record logRecord) error {
+	switch record.Op {
+	case opCreate:
+		link := fromFileLink(record.Link)
+		return s.URLStorage.CreateShortURL(ctx, &link)
+	case opUpdate:
+		link := fromFileLink(record.Link)
+		return s.URLStorage.UpdateLink(ctx, &link)
+	case opDelete:
+		if record.At == nil {
+			return errors.New("delete record without time")
+		}
+		return s.URLStorage.DeleteLink(ctx, record.ShortURL, *record.At)
+	case opExpire:
+		if record.At == nil {
+			return errors.New("expire record without time")
+		}
+		_, err := s.URLStorage.DeleteExpired(ctx, *record.At)
+		return err
+	case opCounter:
+		s.URLStorage.bumpLastID(record.LastID)
+		return nil
+	}
+	return fmt.Errorf("unknown storage log operation %q", record.Op)
+}
+
+func toFileLink(link model.Link) *fileLink {
+	return &fileLink{
+		ID:           link.ID,
+		ShortURL:     link.ShortURL,
+		OriginalURL:  link.OriginalURL,
+		RedirectType: link.RedirectType,
+		ExpiresAt:    link.ExpiresAt,
+		DeletedAt:    link.DeletedAt,
+		Owner:        link.Owner,
+		CreatedAt:    link.CreatedAt,
+	}
+}
+
+func fromFileLink(link *fileLink) model.Link {
+	if link == nil {
+		return model.Link{}
+	}
+	return model.Link{
+		ID:           link.ID,
+		ShortURL:     link.ShortURL,
+		OriginalURL:  link.OriginalURL,
+		RedirectType: link.RedirectType,
+		ExpiresAt:    link.ExpiresAt,
+		DeletedAt:    link.DeletedAt,
+		Owner:        link.Owner,
+		CreatedAt:    link.CreatedAt,
+	}
+}
+
+// syncDir makes a rename inside dir durable. Not every platform allows
+// syncing a directory, so failures are ignored.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+	"urlShortener/internal/model"
+)
+
+func TestFileStorageReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "links.log")
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	past := now.Add(-time.Hour)
+
+	storage, err := NewFileStorage(path, zap.NewNop())
+	require.NoError(t, err)
+
+	require.NoError(t, storage.CreateShortURL(ctx, &model.Link{ID: 1, ShortURL: "b", OriginalURL: "https://example.com/1", Owner: "marketing", CreatedAt: now}))
+	require.NoError(t, storage.CreateShortURL(ctx, &model.Link{ID: 2, ShortURL: "c", OriginalURL: "https://example.com/2", CreatedAt: now}))
+	require.NoError(t, storage.CreateShortURL(ctx, &model.Link{ID: 3, ShortURL: "d", OriginalURL: "https://example.com/3", ExpiresAt: &past}))
+	taken, err := storage.CreateShortURLs(ctx, []*model.Link{
+		{ID: 4, ShortURL: "e", OriginalURL: "https://example.com/4"},
+		{ID: 5, ShortURL: "b", OriginalURL: "https://example.com/5"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{5}, taken)
+	require.NoError(t, storage.UpdateLink(ctx, &model.Link{ShortURL: "c", OriginalURL: "https://example.com/updated", RedirectType: 301}))
+	require.NoError(t, storage.DeleteLink(ctx, "e", now))
+	deleted, err := storage.DeleteExpired(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	require.NoError(t, storage.Close())
+
+	// После перезапуска состояние восстанавливается из журнала
+	reopened, err := NewFileStorage(path, zap.NewNop())
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	link, err := reopened.GetOriginalURL(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, "marketing", link.Owner)
+	assert.True(t, now.Equal(link.CreatedAt))
+
+	link, err = reopened.GetOriginalURL(ctx, "c")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/updated", link.OriginalURL)
+	assert.Equal(t, 301, link.RedirectType)
+
+	link, err = reopened.GetOriginalURL(ctx, "e")
+	require.NoError(t, err)
+	assert.NotNil(t, link.DeletedAt)
+
+	_, err = reopened.GetOriginalURL(ctx, "d")
+	assert.ErrorIs(t, err, ErrLinkNotFound)
+
+	shortURL, err := reopened.CheckDublicate(ctx, "https://example.com/1", "marketing")
+	require.NoError(t, err)
+	assert.Equal(t, "b", shortURL)
+
+	// Счётчик ID продолжается после восстановленных ссылок
+	id, err := reopened.GetNextID(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 5, id)
+}
+
+func TestFileStorageCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "links.log")
+	ctx := context.Background()
+
+	storage, err := NewFileStorage(path, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, storage.CreateShortURL(ctx, &model.Link{ID: 1, ShortURL: "b", OriginalURL: "https://example.com/1"}))
+	for i := 0; i < 5; i++ {
+		require.NoError(t, storage.UpdateLink(ctx, &model.Link{ShortURL: "b", OriginalURL: "https://example.com/new"}))
+	}
+
+	// Запись счётчика ID от сжатия при старте, создание и пять изменений
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 7, bytes.Count(data, []byte("\n")))
+
+	// Сжатие оставляет по одной записи на ссылку и запись счётчика ID
+	require.NoError(t, storage.Compact())
+	require.NoError(t, storage.CreateShortURL(ctx, &model.Link{ID: 2, ShortURL: "c", OriginalURL: "https://example.com/2"}))
+	require.NoError(t, storage.Close())
+
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 3, bytes.Count(data, []byte("\n")))
+
+	// Оборванная последняя запись отбрасывается
+	require.NoError(t, os.WriteFile(path, append(data, []byte(`{"op":"create","link":{"id":3`)...), 0o644))
+	reopened, err := NewFileStorage(path, zap.NewNop())
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	link, err := reopened.GetOriginalURL(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/new", link.OriginalURL)
+	_, err = reopened.GetOriginalURL(ctx, "c")
+	assert.NoError(t, err)
+}
+
+func TestFileStorageKeepsIDsAfterSweep(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "links.log")
+	ctx := context.Background()
+	now := time.Now().UTC()
+	past := now.Add(-time.Hour)
+
+	storage, err := NewFileStorage(path, zap.NewNop())
+	require.NoError(t, err)
+
+	var issued []int
+	for _, shortURL := range []string{"b", "c", "d"} {
+		id, err := storage.GetNextID(ctx)
+		require.NoError(t, err)
+		issued = append(issued, id)
+		link := &model.Link{ID: id, ShortURL: shortURL, OriginalURL: "https://example.com/" + shortURL}
+		if shortURL != "b" {
+			link.ExpiresAt = &past
+		}
+		require.NoError(t, storage.CreateShortURL(ctx, link))
+	}
+
+	// Самые новые ссылки просрочены и удалены, после сжатия в журнале их больше нет
+	deleted, err := storage.DeleteExpired(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+	require.NoError(t, storage.Compact())
+	require.NoError(t, storage.Close())
+
+	reopened, err := NewFileStorage(path, zap.NewNop())
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	// Выданные ранее ID не используются повторно
+	id, err := reopened.GetNextID(ctx)
+	require.NoError(t, err)
+	assert.Greater(t, id, slices.Max(issued))
+}
+
+func TestFileStorageFailedAppend(t *testing.T) {
+	ctx := context.Background()
+	storage, err := NewFileStorage(filepath.Join(t.TempDir(), "links.log"), zap.NewNop())
+	require.NoError(t, err)
+	storage.SyncWrites(true)
+	require.NoError(t, storage.CreateShortURL(ctx, &model.Link{ID: 1, ShortURL: "b", OriginalURL: "https://example.com/1"}))
+	require.NoError(t, storage.Close())
+
+	// Изменение, не попавшее в журнал, не применяется и в памяти
+	assert.Error(t, storage.CreateShortURL(ctx, &model.Link{ID: 2, ShortURL: "c", OriginalURL: "https://example.com/2"}))
+	_, err = storage.GetOriginalURL(ctx, "c")
+	assert.ErrorIs(t, err, ErrLinkNotFound)
+
+	_, err = storage.CreateShortURLs(ctx, []*model.Link{{ID: 3, ShortURL: "d", OriginalURL: "https://example.com/3"}})
+	assert.Error(t, err)
+	exists, err := storage.ShortURLExists(ctx, "d")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	assert.Error(t, storage.UpdateLink(ctx, &model.Link{ShortURL: "b", OriginalURL: "https://example.com/new"}))
+	assert.Error(t, storage.DeleteLink(ctx, "b", time.Now()))
+	link, err := storage.GetOriginalURL(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/1", link.OriginalURL)
+	assert.Nil(t, link.DeletedAt)
+}
+
+func TestFileStorageFailedCompaction(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "links.log")
+	storage, err := NewFileStorage(path, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, storage.CreateShortURL(ctx, &model.Link{ID: 1, ShortURL: "b", OriginalURL: "https://example.com/1"}))
+
+	// Журнал подменяется каталогом, переименование снимка поверх него не удаётся
+	kept := path + ".kept"
+	require.NoError(t, os.Link(path, kept))
+	require.NoError(t, os.Remove(path))
+	require.NoError(t, os.Mkdir(path, 0o755))
+
+	assert.Error(t, storage.Compact())
+	_, err = os.Stat(path + ".tmp")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	// Старый журнал остаётся открытым, запись продолжается
+	require.NoError(t, storage.CreateShortURL(ctx, &model.Link{ID: 2, ShortURL: "c", OriginalURL: "https://example.com/2"}))
+	require.NoError(t, storage.Close())
+
+	require.NoError(t, os.Remove(path))
+	require.NoError(t, os.Rename(kept, path))
+	reopened, err := NewFileStorage(path, zap.NewNop())
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	link, err := reopened.GetOriginalURL(ctx, "c")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/2", link.OriginalURL)
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"urlShortener/internal/model"
+)
+
+// RepositoryObserver receives the timing and outcome of every repository call.
+type RepositoryObserver interface {
+	ObserveRepository(operation string, elapsed time.Duration, err error)
+	LinksCreated(n int)
+}
+
+// InstrumentedRepository reports every SwapRepository call to an observer.
+// ErrLinkNotFound and ErrShortURLExists are answers, not failures, and are
+// reported as successes.
+type InstrumentedRepository struct {
+	repo     SwapRepository
+	observer RepositoryObserver
+}
+
+func NewInstrumentedRepository(repo SwapRepository, observer RepositoryObserver) *InstrumentedRepository {
+	return &InstrumentedRepository{
+		repo:     repo,
+		observer: observer,
+	}
+}
+
+func (r *InstrumentedRepository) observe(operation string, start time.Time, err error) {
+	if errors.Is(err, ErrLinkNotFound) || errors.Is(err, ErrShortURLExists) {
+		err = nil
+	}
+	r.observer.ObserveRepository(operation, time.Since(start), err)
+}
+
+func (r *InstrumentedRepository) CreateShortURL(ctx context.Context, link *model.Link) error {
+	start := time.Now()
+	err := r.repo.CreateShortURL(ctx, link)
+	r.observe("create_short_url", start, err)
+	if err == nil {
+		r.observer.LinksCreated(1)
+	}
+	return err
+}
+
+func (r *InstrumentedRepository) CreateShortURLs(ctx context.Context, links []*model.Link) ([]int, error) {
+	start := time.Now()
+	taken, err := r.repo.CreateShortURLs(ctx, links)
+	r.observe("create_short_urls", start, err)
+	if err == nil {
+		r.observer.LinksCreated(len(links) - len(taken))
+	}
+	return taken, err
+}
+
+func (r *InstrumentedRepository) GetOriginalURL(ctx context.Context, shortURL string) (*model.Link, error) {
+	start := time.Now()
+	link, err := r.repo.GetOriginalURL(ctx, shortURL)
+	r.observe("get_original_url", start, err)
+	return link, err
+}
+
+func (r *InstrumentedRepository) GetOriginalURLs(ctx context.Context, shortURLs []string) (map[string]model.Link, error) {
+	start := time.Now()
+	links, err := r.repo.GetOriginalURLs(ctx, shortURLs)
+	r.observe("get_original_urls", start, err)
+	return links, err
+}
+
+func (r *InstrumentedRepository) CheckDublicate(ctx context.Context, originalURL string, owner string) (string, error) {
+	start := time.Now()
+	shortURL, err := r.repo.CheckDublicate(ctx, originalURL, owner)
+	r.observe("check_dublicate", start, err)
+	return shortURL, err
+}
+
+func (r *InstrumentedRepository) GetNextID(ctx context.Context) (int, error) {
+	start := time.Now()
+	id, err := r.repo.GetNextID(ctx)
+	r.observe("get_next_id", start, err)
+	return id, err
+}
+
+func (r *InstrumentedRepository) GetNextIDs(ctx context.Context, n int) ([]int, error) {
+	start := time.Now()
+	ids, err := r.repo.GetNextIDs(ctx, n)
+	r.observe("get_next_ids", start, err)
+	return ids, err
+}
+
+func (r *InstrumentedRepository) ShortURLExists(ctx context.Context, shortURL string) (bool, error) {
+	start := time.Now()
+	exists, err := r.repo.ShortURLExists(ctx, shortURL)
+	r.observe("short_url_exists", start, err)
+	return exists, err
+}
+
+func (r *InstrumentedRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	start := time.Now()
+	deleted, err := r.repo.DeleteExpired(ctx, now)
+	r.observe("delete_expired", start, err)
+	return deleted, err
+}
+
+func (r *InstrumentedRepository) UpdateLink(ctx context.Context, link *model.Link) error {
+	start := time.Now()
+	err := r.repo.UpdateLink(ctx, link)
+	r.observe("update_link", start, err)
+	return err
+}
+
+func (r *InstrumentedRepository) DeleteLink(ctx context.Context, shortURL string, now time.Time) error {
+	start := time.Now()
+	err := r.repo.DeleteLink(ctx, shortURL, now)
+	r.observe("delete_link", start, err)
+	return err
+}
+
+func (r *InstrumentedRepository) ListLinks(ctx context.Context, query model.ListQuery) ([]model.Link, error) {
+	start := time.Now()
+	links, err := r.repo.ListLinks(ctx, query)
+	r.observe("list_links", start, err)
+	return links, err
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"sync"
+	"testing"
+	"time"
+	"urlShortener/internal/model"
+)
+
+type recordingObserver struct {
+	mu         sync.Mutex
+	operations []string
+	failed     []string
+	created    int
+}
+
+func (o *recordingObserver) ObserveRepository(operation string, elapsed time.Duration, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.operations = append(o.operations, operation)
+	if err != nil {
+		o.failed = append(o.failed, operation)
+	}
+}
+
+func (o *recordingObserver) LinksCreated(n int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.created += n
+}
+
+func TestInstrumentedRepositoryConformance(t *testing.T) {
+	runConformance(t, func(t *testing.T) SwapRepository {
+		return NewInstrumentedRepository(NewURLStorage(zap.NewNop()), &recordingObserver{})
+	})
+}
+
+func TestInstrumentedRepository(t *testing.T) {
+	ctx := context.Background()
+	observer := &recordingObserver{}
+	repo := NewInstrumentedRepository(NewURLStorage(zap.NewNop()), observer)
+
+	b, c, taken := conformanceLink(1, "b", "https://example.com/1"), conformanceLink(2, "c", "https://example.com/2"), conformanceLink(3, "b", "https://example.com/3")
+	require.NoError(t, repo.CreateShortURL(ctx, &b))
+	_, err := repo.CreateShortURLs(ctx, []*model.Link{&c, &taken})
+	require.NoError(t, err)
+
+	// Ненайденная ссылка и занятый код — ответы, а не сбои хранилища
+	_, err = repo.GetOriginalURL(ctx, "missing")
+	assert.ErrorIs(t, err, ErrLinkNotFound)
+	assert.ErrorIs(t, repo.CreateShortURL(ctx, &taken), ErrShortURLExists)
+
+	assert.Equal(t, []string{"create_short_url", "create_short_urls", "get_original_url", "create_short_url"}, observer.operations)
+	assert.Empty(t, observer.failed)
+	assert.Equal(t, 2, observer.created)
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"sort"
+	"sync"
+	"time"
+	"urlShortener/internal/model"
+)
+
+type KeyStorage struct {
+	mu     sync.RWMutex
+	lastID int
+	keys   map[string]*model.APIKey // Key hash -> Key
+	logger *zap.Logger
+}
+
+func NewKeyStorage(logger *zap.Logger) *KeyStorage {
+	return &KeyStorage{
+		keys:   make(map[string]*model.APIKey),
+		logger: logger,
+	}
+}
+
+func (s *KeyStorage) CreateKey(ctx context.Context, key *model.APIKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.keys[key.Hash]; exists {
+		return ErrKeyExists
+	}
+	for _, stored := range s.keys {
+		if stored.Name == key.Name && stored.RevokedAt == nil {
+			return ErrKeyNameTaken
+		}
+	}
+
+	s.lastID++
+	key.ID = s.lastID
+	stored := *key
+	s.keys[key.Hash] = &stored
+	return nil
+}
+
+func (s *KeyStorage) GetKeyByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, exists := s.keys[hash]
+	if !exists {
+		return nil, ErrKeyNotFound
+	}
+	found := *key
+	return &found, nil
+}
+
+func (s *KeyStorage) ListKeys(ctx context.Context) ([]model.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]model.APIKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, *key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys, nil
+}
+
+func (s *KeyStorage) RevokeKey(ctx context.Context, id int, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range s.keys {
+		if key.ID == id && key.RevokedAt == nil {
+			key.RevokedAt = &now
+			return nil
+		}
+	}
+	return ErrKeyNotFound
+}
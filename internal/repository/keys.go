@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+	"time"
+	"urlShortener/internal/initialize"
+	"urlShortener/internal/model"
+)
+
+type KeyRepository interface {
+	CreateKey(ctx context.Context, key *model.APIKey) error
+	GetKeyByHash(ctx context.Context, hash string) (*model.APIKey, error)
+	ListKeys(ctx context.Context) ([]model.APIKey, error)
+	RevokeKey(ctx context.Context, id int, now time.Time) error
+}
+
+type PgKeyRepository struct {
+	pool   PgxIface
+	logger *zap.Logger
+}
+
+func NewKeyRepository(dbInstance *initialize.DB, logger *zap.Logger) *PgKeyRepository {
+	return &PgKeyRepository{
+		pool:   dbInstance.Pool,
+		logger: logger,
+	}
+}
+
+func (r *PgKeyRepository) CreateKey(ctx context.Context, key *model.APIKey) error {
+	err := r.pool.QueryRow(ctx,
+		"INSERT INTO api_keys (name, prefix, key_hash, created_at) VALUES ($1, $2, $3, $4) RETURNING id",
+		key.Name, key.Prefix, key.Hash, key.CreatedAt).Scan(&key.ID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			switch pgErr.ConstraintName {
+			case "api_keys_key_hash_key":
+				return ErrKeyExists
+			case "api_keys_name_idx":
+				return ErrKeyNameTaken
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *PgKeyRepository) GetKeyByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	key := &model.APIKey{Hash: hash}
+	err := r.pool.QueryRow(ctx, "SELECT id, name, prefix, created_at, revoked_at FROM api_keys WHERE key_hash = $1", hash).
+		Scan(&key.ID, &key.Name, &key.Prefix, &key.CreatedAt, &key.RevokedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+func (r *PgKeyRepository) ListKeys(ctx context.Context) ([]model.APIKey, error) {
+	rows, err := r.pool.Query(ctx, "SELECT id, name, prefix, created_at, revoked_at FROM api_keys ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []model.APIKey{}
+	for rows.Next() {
+		var key model.APIKey
+		if err := rows.Scan(&key.ID, &key.Name, &key.Prefix, &key.CreatedAt, &key.RevokedAt); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *PgKeyRepository) RevokeKey(ctx context.Context, id int, now time.Time) error {
+	tag, err := r.pool.Exec(ctx, "UPDATE api_keys SET revoked_at = $2 WHERE id = $1 AND revoked_at IS NULL", id, now)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrKeyNotFound
+	}
+	return nil
+}
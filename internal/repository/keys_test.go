@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+	"urlShortener/internal/model"
+)
+
+func TestKeyStorageCreateKey(t *testing.T) {
+	storage := NewKeyStorage(zap.NewNop())
+	key := &model.APIKey{Name: "marketing", Hash: "hash-1", CreatedAt: time.Now()}
+
+	require.NoError(t, storage.CreateKey(context.Background(), key))
+	assert.Equal(t, 1, key.ID)
+
+	// Повторный ключ с тем же хешем не создаётся
+	err := storage.CreateKey(context.Background(), &model.APIKey{Name: "marketing", Hash: "hash-1"})
+	assert.ErrorIs(t, err, ErrKeyExists)
+
+	// Имя ключа — владелец ссылок, у действующих ключей оно не повторяется
+	err = storage.CreateKey(context.Background(), &model.APIKey{Name: "marketing", Hash: "hash-2"})
+	assert.ErrorIs(t, err, ErrKeyNameTaken)
+
+	// После отзыва имя можно выдать новому ключу
+	require.NoError(t, storage.RevokeKey(context.Background(), key.ID, time.Now()))
+	assert.NoError(t, storage.CreateKey(context.Background(), &model.APIKey{Name: "marketing", Hash: "hash-2"}))
+
+	keys, _ := storage.ListKeys(context.Background())
+	assert.Len(t, keys, 2)
+}
+
+func TestPgCreateKey(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock pool: %v", err)
+	}
+	defer mockPool.Close()
+
+	repo := PgKeyRepository{pool: mockPool, logger: zap.NewNop()}
+	now := time.Now()
+
+	// Случай, когда ключ создан
+	mockPool.ExpectQuery("INSERT INTO api_keys").
+		WithArgs("marketing", "sk_abcde", "hash-1", now).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(7))
+
+	key := &model.APIKey{Name: "marketing", Prefix: "sk_abcde", Hash: "hash-1", CreatedAt: now}
+	assert.NoError(t, repo.CreateKey(context.Background(), key))
+	assert.Equal(t, 7, key.ID)
+
+	// Случай, когда ключ с таким хешем уже есть
+	mockPool.ExpectQuery("INSERT INTO api_keys").
+		WithArgs("marketing", "sk_abcde", "hash-1", now).
+		WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "api_keys_key_hash_key"})
+
+	err = repo.CreateKey(context.Background(), &model.APIKey{Name: "marketing", Prefix: "sk_abcde", Hash: "hash-1", CreatedAt: now})
+	assert.ErrorIs(t, err, ErrKeyExists)
+
+	// Случай, когда имя занято действующим ключом
+	mockPool.ExpectQuery("INSERT INTO api_keys").
+		WithArgs("marketing", "sk_fghij", "hash-2", now).
+		WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "api_keys_name_idx"})
+
+	err = repo.CreateKey(context.Background(), &model.APIKey{Name: "marketing", Prefix: "sk_fghij", Hash: "hash-2", CreatedAt: now})
+	assert.ErrorIs(t, err, ErrKeyNameTaken)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+	"urlShortener/internal/initialize"
+)
+
+// notifyPayloadLimit keeps a pg_notify payload below Postgres' 8000 byte limit.
+const notifyPayloadLimit = 7900
+
+const (
+	listenRetryMin = time.Second
+	listenRetryMax = 30 * time.Second
+)
+
+// NotifyChanges makes the repository announce every code it creates,
+// updates or deletes on channel, so other replicas can drop cached copies.
+func (r *ShortenerRepository) NotifyChanges(channel string) {
+	r.channel = channel
+}
+
+// notify publishes codes after a successful write. A lost notification only
+// leaves other replicas with a stale entry until the cache TTL, so the
+// write itself does not fail.
+func (r *ShortenerRepository) notify(ctx context.Context, shortURLs ...string) {
+	if r.channel == "" || len(shortURLs) == 0 {
+		return
+	}
+	for _, payload := range changePayloads(shortURLs) {
+		if _, err := r.pool.Exec(ctx, "SELECT pg_notify($1, $2)", r.channel, payload); err != nil {
+			r.logger.Warn("error publishing link change", zap.String("channel", r.channel), zap.Error(err))
+			return
+		}
+	}
+}
+
+// changePayloads joins codes with spaces, which aliases and generated codes
+// never contain, splitting them so each payload fits a notification.
+func changePayloads(shortURLs []string) []string {
+	var payloads []string
+	var sb strings.Builder
+	for _, shortURL := range shortURLs {
+		if sb.Len() > 0 && sb.Len()+1+len(shortURL) > notifyPayloadLimit {
+			payloads = append(payloads, sb.String())
+			sb.Reset()
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(shortURL)
+	}
+	if sb.Len() > 0 {
+		payloads = append(payloads, sb.String())
+	}
+	return payloads
+}
+
+// Invalidator is the part of CachingRepository a LinkListener drives.
+type Invalidator interface {
+	Invalidate(shortURLs ...string)
+	Purge()
+}
+
+// listenConn is the dedicated connection LISTEN runs on, *pgx.Conn in production.
+type listenConn interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	WaitForNotification(ctx context.Context) (*pgconn.Notification, error)
+	Close(ctx context.Context) error
+}
+
+// LinkListener evicts codes that other replicas announce with NotifyChanges
+// from the local cache. It holds its own connection outside the pool and
+// reconnects with backoff when it drops.
+type LinkListener struct {
+	connect  func(ctx context.Context) (listenConn, error)
+	channel  string
+	cache    Invalidator
+	retryMin time.Duration
+	retryMax time.Duration
+	logger   *zap.Logger
+}
+
+func NewLinkListener(dbInstance *initialize.DB, channel string, cache Invalidator, logger *zap.Logger) *LinkListener {
+	connConfig := dbInstance.Pool.Config().ConnConfig
+	return &LinkListener{
+		connect: func(ctx context.Context) (listenConn, error) {
+			return pgx.ConnectConfig(ctx, connConfig)
+		},
+		channel:  channel,
+		cache:    cache,
+		retryMin: listenRetryMin,
+		retryMax: listenRetryMax,
+		logger:   logger,
+	}
+}
+
+// Run listens until ctx is done.
+func (l *LinkListener) Run(ctx context.Context) {
+	retry := l.retryMin
+	for {
+		err := l.listen(ctx, func() { retry = l.retryMin })
+		if ctx.Err() != nil {
+			return
+		}
+		l.logger.Warn("link change listener disconnected", zap.String("channel", l.channel), zap.Duration("retry", retry), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+		retry = min(retry*2, l.retryMax)
+	}
+}
+
+// listen runs one connection until it fails. Changes published while no
+// connection was listening are lost, so the cache is purged once LISTEN
+// is in place again.
+func (l *LinkListener) listen(ctx context.Context, connected func()) error {
+	conn, err := l.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{l.channel}.Sanitize()); err != nil {
+		return err
+	}
+	l.cache.Purge()
+	connected()
+	l.logger.Info("listening for link changes", zap.String("channel", l.channel))
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		l.cache.Invalidate(strings.Fields(notification.Payload)...)
+	}
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"urlShortener/internal/model"
+)
+
+func TestChangePayloads(t *testing.T) {
+	assert.Empty(t, changePayloads(nil))
+	assert.Equal(t, []string{"b c"}, changePayloads([]string{"b", "c"}))
+
+	// Большой пакет делится на уведомления не длиннее лимита
+	codes := make([]string, 3000)
+	for i := range codes {
+		codes[i] = fmt.Sprintf("code%d", i)
+	}
+	payloads := changePayloads(codes)
+	require.Greater(t, len(payloads), 1)
+	var joined []string
+	for _, payload := range payloads {
+		assert.LessOrEqual(t, len(payload), notifyPayloadLimit)
+		joined = append(joined, strings.Fields(payload)...)
+	}
+	assert.Equal(t, codes, joined)
+}
+
+func TestShortenerRepositoryNotify(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := ShortenerRepository{pool: mockPool, logger: zap.NewNop()}
+	repo.NotifyChanges("link_changes")
+
+	// Случай, когда изменение публикуется после записи
+	mockPool.ExpectExec("UPDATE links SET original_url").
+		WithArgs("b", "https://example.com/b", 0, (*time.Time)(nil)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockPool.ExpectExec(`SELECT pg_notify\(\$1, \$2\)`).
+		WithArgs("link_changes", "b").
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+
+	assert.NoError(t, repo.UpdateLink(context.Background(), &model.Link{ShortURL: "b", OriginalURL: "https://example.com/b"}))
+
+	// Случай, когда ошибка публикации не ломает удаление
+	now := time.Now()
+	mockPool.ExpectExec("UPDATE links SET deleted_at").
+		WithArgs("b", now).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockPool.ExpectExec("SELECT pg_notify").
+		WithArgs("link_changes", "b").
+		WillReturnError(fmt.Errorf("database error"))
+
+	assert.NoError(t, repo.DeleteLink(context.Background(), "b", now))
+
+	// Случай, когда ничего не изменилось и публиковать нечего
+	mockPool.ExpectExec("UPDATE links SET deleted_at").
+		WithArgs("b", now).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	assert.ErrorIs(t, repo.DeleteLink(context.Background(), "b", now), ErrLinkNotFound)
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+type fakeListenConn struct {
+	notifications chan *pgconn.Notification // закрытый канал означает обрыв соединения
+	listened      chan string
+}
+
+func (c *fakeListenConn) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	c.listened <- sql
+	return pgconn.CommandTag{}, nil
+}
+
+func (c *fakeListenConn) WaitForNotification(ctx context.Context) (*pgconn.Notification, error) {
+	select {
+	case notification, ok := <-c.notifications:
+		if !ok {
+			return nil, errors.New("connection lost")
+		}
+		return notification, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *fakeListenConn) Close(ctx context.Context) error { return nil }
+
+type recordingInvalidator struct {
+	mu          sync.Mutex
+	invalidated []string
+	purges      int
+}
+
+func (r *recordingInvalidator) Invalidate(shortURLs ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.invalidated = append(r.invalidated, shortURLs...)
+}
+
+func (r *recordingInvalidator) Purge() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.purges++
+}
+
+func (r *recordingInvalidator) state() ([]string, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.invalidated...), r.purges
+}
+
+func TestLinkListenerReconnects(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	first := &fakeListenConn{notifications: make(chan *pgconn.Notification), listened: make(chan string, 1)}
+	second := &fakeListenConn{notifications: make(chan *pgconn.Notification), listened: make(chan string, 1)}
+	conns := make(chan *fakeListenConn, 2)
+	conns <- first
+	conns <- second
+
+	attempts := 0
+	cache := &recordingInvalidator{}
+	listener := &LinkListener{
+		connect: func(ctx context.Context) (listenConn, error) {
+			attempts++
+			// Первая попытка подключения неудачна
+			if attempts == 1 {
+				return nil, errors.New("connection refused")
+			}
+			return <-conns, nil
+		},
+		channel:  "link_changes",
+		cache:    cache,
+		retryMin: time.Millisecond,
+		retryMax: 5 * time.Millisecond,
+		logger:   zap.NewNop(),
+	}
+
+	done := make(chan struct{})
+	go func() {
+		listener.Run(ctx)
+		close(done)
+	}()
+
+	assert.Equal(t, `LISTEN "link_changes"`, <-first.listened)
+	first.notifications <- &pgconn.Notification{Channel: "link_changes", Payload: "b c"}
+	close(first.notifications)
+
+	// После переподключения кеш сбрасывается целиком: уведомления могли потеряться
+	<-second.listened
+	second.notifications <- &pgconn.Notification{Channel: "link_changes", Payload: "d"}
+	second.notifications <- &pgconn.Notification{Channel: "link_changes", Payload: "e"}
+
+	cancel()
+	<-done
+
+	invalidated, purges := cache.state()
+	assert.Equal(t, []string{"b", "c", "d", "e"}, invalidated)
+	assert.Equal(t, 2, purges)
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+	"urlShortener/internal/model"
+)
+
+const rateLimitPruneInterval = time.Minute
+
+type tokenBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	fullAt    time.Time
+}
+
+// RateLimitStorage keeps token buckets in process memory, limits are per replica.
+type RateLimitStorage struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastPrune time.Time
+}
+
+func NewRateLimitStorage() *RateLimitStorage {
+	return &RateLimitStorage{
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// Take charges cost tokens to the bucket under key if it holds at least limit.Need(cost).
+func (s *RateLimitStorage) Take(ctx context.Context, key string, limit model.RateLimit, cost int, now time.Time) (model.RateDecision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune(now)
+
+	bucket, exists := s.buckets[key]
+	if !exists {
+		bucket = &tokenBucket{tokens: float64(limit.Burst), updatedAt: now}
+		s.buckets[key] = bucket
+	}
+	bucket.tokens = limit.Refill(bucket.tokens, now.Sub(bucket.updatedAt))
+	bucket.updatedAt = now
+
+	need := limit.Need(cost)
+	allowed := bucket.tokens >= need
+	if allowed {
+		bucket.tokens -= float64(cost)
+	}
+	decision := limit.Decision(allowed, bucket.tokens, need)
+	bucket.fullAt = now.Add(decision.Reset)
+	return decision, nil
+}
+
+// prune drops buckets that have refilled completely, they are
+// indistinguishable from a fresh one.
+func (s *RateLimitStorage) prune(now time.Time) {
+	if now.Sub(s.lastPrune) < rateLimitPruneInterval {
+		return
+	}
+	s.lastPrune = now
+	for key, bucket := range s.buckets {
+		if !now.Before(bucket.fullAt) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+	"time"
+	"urlShortener/internal/initialize"
+	"urlShortener/internal/model"
+)
+
+type RateLimitRepository interface {
+	Take(ctx context.Context, key string, limit model.RateLimit, cost int, now time.Time) (model.RateDecision, error)
+}
+
+// refillExpr is the bucket level at $4 for a bucket of $2 tokens refilled at $3 per second.
+const refillExpr = "LEAST($2::float8, r.tokens + GREATEST(EXTRACT(EPOCH FROM ($4 - r.updated_at))::float8, 0) * $3::float8)"
+
+// PgRateLimitRepository keeps token buckets in the rate_limits table so
+// every replica draws from the same budget.
+type PgRateLimitRepository struct {
+	pool   PgxIface
+	logger *zap.Logger
+}
+
+func NewRateLimitRepository(dbInstance *initialize.DB, logger *zap.Logger) *PgRateLimitRepository {
+	return &PgRateLimitRepository{
+		pool:   dbInstance.Pool,
+		logger: logger,
+	}
+}
+
+// Take refills the bucket and takes cost tokens in a single statement. A
+// bucket holding less than limit.Need(cost) is left untouched and no row
+// comes back.
+func (r *PgRateLimitRepository) Take(ctx context.Context, key string, limit model.RateLimit, cost int, now time.Time) (model.RateDecision, error) {
+	need := limit.Need(cost)
+	var tokens float64
+	err := r.pool.QueryRow(ctx,
+		"INSERT INTO rate_limits AS r (key, tokens, updated_at) VALUES ($1, $2::float8 - $5::float8, $4) "+
+			"ON CONFLICT (key) DO UPDATE SET tokens = "+refillExpr+" - $5::float8, updated_at = $4 "+
+			"WHERE "+refillExpr+" >= $6::float8 RETURNING tokens",
+		key, float64(limit.Burst), limit.Rate, now, float64(cost), need).Scan(&tokens)
+	if err == nil {
+		return limit.Decision(true, tokens, need), nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return model.RateDecision{}, err
+	}
+
+	var updatedAt time.Time
+	err = r.pool.QueryRow(ctx, "SELECT tokens, updated_at FROM rate_limits WHERE key = $1", key).
+		Scan(&tokens, &updatedAt)
+	if err != nil {
+		return model.RateDecision{}, err
+	}
+	return limit.Decision(false, limit.Refill(tokens, now.Sub(updatedAt)), need), nil
+}
+
+// DeleteIdle removes buckets untouched since before, a missing bucket is a
+// full one. Buckets in debt after a batch are kept until it is paid off.
+func (r *PgRateLimitRepository) DeleteIdle(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, "DELETE FROM rate_limits WHERE updated_at < $1 AND tokens >= 0", before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+	"urlShortener/internal/model"
+)
+
+func TestRateLimitStorageTake(t *testing.T) {
+	storage := NewRateLimitStorage()
+	limit := model.RateLimit{Rate: 1, Burst: 2}
+	now := time.Now()
+
+	// Запас корзины расходуется, затем запросы отклоняются
+	decision, _ := storage.Take(context.Background(), "ip:10.0.0.1", limit, 1, now)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, 1, decision.Remaining)
+
+	decision, _ = storage.Take(context.Background(), "ip:10.0.0.1", limit, 1, now)
+	assert.True(t, decision.Allowed)
+
+	decision, _ = storage.Take(context.Background(), "ip:10.0.0.1", limit, 1, now)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, time.Second, decision.RetryAfter)
+	assert.Equal(t, 2*time.Second, decision.Reset)
+
+	// Другой клиент расходует свою корзину
+	decision, _ = storage.Take(context.Background(), "ip:10.0.0.2", limit, 1, now)
+	assert.True(t, decision.Allowed)
+
+	// Корзина пополняется со временем
+	decision, _ = storage.Take(context.Background(), "ip:10.0.0.1", limit, 1, now.Add(time.Second))
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, 0, decision.Remaining)
+
+	// Пакет списывает токен за каждый элемент и может увести корзину в долг
+	decision, _ = storage.Take(context.Background(), "ip:10.0.0.4", limit, 5, now)
+	assert.True(t, decision.Allowed)
+	decision, _ = storage.Take(context.Background(), "ip:10.0.0.4", limit, 1, now.Add(time.Second))
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, 3*time.Second, decision.RetryAfter)
+
+	// Полностью пополненные корзины удаляются
+	storage.Take(context.Background(), "ip:10.0.0.3", limit, 1, now.Add(time.Hour))
+	assert.Len(t, storage.buckets, 1)
+}
+
+func TestPgRateLimitTake(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock pool: %v", err)
+	}
+	defer mockPool.Close()
+
+	repo := PgRateLimitRepository{pool: mockPool}
+	limit := model.RateLimit{Rate: 0.5, Burst: 10}
+	now := time.Now()
+
+	// Случай, когда токен списан
+	mockPool.ExpectQuery("INSERT INTO rate_limits").
+		WithArgs("create:ip:10.0.0.1", 10.0, 0.5, now, 1.0, 1.0).
+		WillReturnRows(pgxmock.NewRows([]string{"tokens"}).AddRow(4.5))
+
+	decision, err := repo.Take(context.Background(), "create:ip:10.0.0.1", limit, 1, now)
+	assert.NoError(t, err)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, 4, decision.Remaining)
+	assert.Equal(t, 10, decision.Limit)
+
+	// Случай, когда токенов не осталось
+	mockPool.ExpectQuery("INSERT INTO rate_limits").
+		WithArgs("create:ip:10.0.0.1", 10.0, 0.5, now, 1.0, 1.0).
+		WillReturnError(pgx.ErrNoRows)
+	mockPool.ExpectQuery("SELECT tokens, updated_at FROM rate_limits").
+		WithArgs("create:ip:10.0.0.1").
+		WillReturnRows(pgxmock.NewRows([]string{"tokens", "updated_at"}).AddRow(0.0, now.Add(-time.Second)))
+
+	decision, err = repo.Take(context.Background(), "create:ip:10.0.0.1", limit, 1, now)
+	assert.NoError(t, err)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, time.Second, decision.RetryAfter)
+
+	// Случай, когда ошибка при выполнении запроса
+	mockPool.ExpectQuery("INSERT INTO rate_limits").
+		WillReturnError(fmt.Errorf("database error"))
+
+	_, err = repo.Take(context.Background(), "create:ip:10.0.0.1", limit, 1, now)
+	assert.Error(t, err)
+}
@@ -3,28 +3,57 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"go.uber.org/zap"
+	"strconv"
+	"strings"
+	"time"
 	"urlShortener/internal/initialize"
+	"urlShortener/internal/model"
 )
 
 type SwapRepository interface {
-	CreateShortURL(ctx context.Context, id int, shortURL string, originalURL string) error
-	GetOriginalURL(ctx context.Context, shortURL string) (string, error)
-	CheckDublicate(ctx context.Context, originalURL string) (string, error)
+	CreateShortURL(ctx context.Context, link *model.Link) error
+	CreateShortURLs(ctx context.Context, links []*model.Link) ([]int, error)
+	GetOriginalURL(ctx context.Context, shortURL string) (*model.Link, error)
+	GetOriginalURLs(ctx context.Context, shortURLs []string) (map[string]model.Link, error)
+	CheckDublicate(ctx context.Context, originalURL string, owner string) (string, error)
 	GetNextID(ctx context.Context) (int, error)
+	GetNextIDs(ctx context.Context, n int) ([]int, error)
+	ShortURLExists(ctx context.Context, shortURL string) (bool, error)
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+	UpdateLink(ctx context.Context, link *model.Link) error
+	DeleteLink(ctx context.Context, shortURL string, now time.Time) error
+	ListLinks(ctx context.Context, query model.ListQuery) ([]model.Link, error)
+}
+
+// bulkInsertRows keeps a multi-row INSERT of links or clicks well below the 65535 parameter limit.
+const bulkInsertRows = 1000
+
+const linkColumns = "id, short_url, original_url, redirect_type, expires_at, deleted_at, owner, created_at"
+
+// hostExpr extracts the lowercased host from original_url for ListQuery.Host filtering.
+const hostExpr = `lower(substring(original_url from '^[A-Za-z][A-Za-z0-9+.-]*://(?:[^@/]*@)?([^/:?#]+)'))`
+
+// linkFields are the scan targets for linkColumns.
+func linkFields(link *model.Link) []any {
+	return []any{&link.ID, &link.ShortURL, &link.OriginalURL, &link.RedirectType, &link.ExpiresAt, &link.DeletedAt, &link.Owner, &link.CreatedAt}
 }
 
 type PgxIface interface {
 	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
 	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
 }
 
 type ShortenerRepository struct {
-	pool   PgxIface
-	err    error
-	logger *zap.Logger
+	pool    PgxIface
+	err     error
+	channel string // pg_notify channel for link changes, empty when nobody listens
+	logger  *zap.Logger
 }
 
 func NewShortenerRepository(dbInstance *initialize.DB, logger *zap.Logger) (*ShortenerRepository, error) {
@@ -42,29 +71,67 @@ func NewShortenerRepository(dbInstance *initialize.DB, logger *zap.Logger) (*Sho
 	}, nil
 }
 
-func (r *ShortenerRepository) CreateShortURL(ctx context.Context, id int, shortURL string, originalURL string) error {
-	_, err := r.pool.Exec(ctx, "INSERT INTO links (id, short_url, original_url) VALUES ($1, $2, $3)", id, shortURL, originalURL)
+func (r *ShortenerRepository) CreateShortURL(ctx context.Context, link *model.Link) error {
+	_, err := r.pool.Exec(ctx,
+		"INSERT INTO links (id, short_url, original_url, redirect_type, expires_at, owner, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)",
+		link.ID, link.ShortURL, link.OriginalURL, link.RedirectType, link.ExpiresAt, link.Owner, link.CreatedAt)
 	if err != nil {
+		if isShortURLConflict(err) {
+			return ErrShortURLExists
+		}
 		return err
 	}
+	r.notify(ctx, link.ShortURL)
 	return nil
 }
 
-func (r *ShortenerRepository) GetOriginalURL(ctx context.Context, shortURL string) (string, error) {
-	var originalURL string
-	err := r.pool.QueryRow(ctx, "SELECT original_url FROM links WHERE short_url = $1", shortURL).Scan(&originalURL)
+// isShortURLConflict reports whether err is a unique violation (SQLSTATE 23505) on links.short_url.
+func isShortURLConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "links_short_url_idx"
+}
+
+func (r *ShortenerRepository) GetOriginalURL(ctx context.Context, shortURL string) (*model.Link, error) {
+	link := &model.Link{ShortURL: shortURL}
+	err := r.pool.QueryRow(ctx, "SELECT "+linkColumns+" FROM links WHERE short_url = $1", shortURL).
+		Scan(linkFields(link)...)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return "", ErrLinkNotFound
+			return nil, ErrLinkNotFound
 		}
-		return "", err
+		return nil, err
 	}
-	return originalURL, nil
+	return link, nil
 }
 
-func (r *ShortenerRepository) CheckDublicate(ctx context.Context, originalURL string) (string, error) {
+// GetOriginalURLs looks up many codes in one query. Codes that do not exist
+// are missing from the result.
+func (r *ShortenerRepository) GetOriginalURLs(ctx context.Context, shortURLs []string) (map[string]model.Link, error) {
+	rows, err := r.pool.Query(ctx, "SELECT "+linkColumns+" FROM links WHERE short_url = ANY($1)", shortURLs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := make(map[string]model.Link, len(shortURLs))
+	for rows.Next() {
+		var link model.Link
+		if err := rows.Scan(linkFields(&link)...); err != nil {
+			return nil, err
+		}
+		links[link.ShortURL] = link
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+func (r *ShortenerRepository) CheckDublicate(ctx context.Context, originalURL string, owner string) (string, error) {
 	var dublicateURL string
-	err := r.pool.QueryRow(ctx, "SELECT short_url FROM links WHERE original_url = $1", originalURL).Scan(&dublicateURL)
+	err := r.pool.QueryRow(ctx,
+		"SELECT short_url FROM links WHERE original_url = $1 AND owner = $2 AND expires_at IS NULL AND deleted_at IS NULL ORDER BY id LIMIT 1",
+		originalURL, owner).Scan(&dublicateURL)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return "", ErrLinkNotFound
@@ -74,11 +141,172 @@ func (r *ShortenerRepository) CheckDublicate(ctx context.Context, originalURL st
 	return dublicateURL, nil
 }
 
+// CreateShortURLs inserts links with multi-row INSERTs in one transaction.
+// Links whose short URL is already taken are skipped, their IDs are returned.
+func (r *ShortenerRepository) CreateShortURLs(ctx context.Context, links []*model.Link) ([]int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	inserted := make(map[int]struct{}, len(links))
+	for start := 0; start < len(links); start += bulkInsertRows {
+		chunk := links[start:min(start+bulkInsertRows, len(links))]
+
+		var sb strings.Builder
+		sb.WriteString("INSERT INTO links (id, short_url, original_url, redirect_type, expires_at, owner, created_at) VALUES ")
+		args := make([]any, 0, len(chunk)*7)
+		for i, link := range chunk {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			n := i * 7
+			fmt.Fprintf(&sb, "($%d, $%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6, n+7)
+			args = append(args, link.ID, link.ShortURL, link.OriginalURL, link.RedirectType, link.ExpiresAt, link.Owner, link.CreatedAt)
+		}
+		sb.WriteString(" ON CONFLICT (short_url) DO NOTHING RETURNING id")
+
+		rows, err := tx.Query(ctx, sb.String(), args...)
+		if err != nil {
+			return nil, err
+		}
+		ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			inserted[id] = struct{}{}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	var taken []int
+	created := make([]string, 0, len(inserted))
+	for _, link := range links {
+		if _, ok := inserted[link.ID]; !ok {
+			taken = append(taken, link.ID)
+		} else {
+			created = append(created, link.ShortURL)
+		}
+	}
+	r.notify(ctx, created...)
+	return taken, nil
+}
+
+// GetNextID reserves an ID from the links sequence, so concurrent callers never get the same one.
 func (r *ShortenerRepository) GetNextID(ctx context.Context) (int, error) {
 	var id int
-	err := r.pool.QueryRow(ctx, "SELECT COALESCE(MAX(id), 0) + 1 FROM links").Scan(&id)
+	err := r.pool.QueryRow(ctx, "SELECT nextval('links_id_seq')").Scan(&id)
 	if err != nil {
 		return 0, err
 	}
 	return id, nil
 }
+
+func (r *ShortenerRepository) GetNextIDs(ctx context.Context, n int) ([]int, error) {
+	rows, err := r.pool.Query(ctx, "SELECT nextval('links_id_seq') FROM generate_series(1, $1)", n)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int])
+}
+
+func (r *ShortenerRepository) ShortURLExists(ctx context.Context, shortURL string) (bool, error) {
+	var exists bool
+	err := r.pool.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM links WHERE short_url = $1)", shortURL).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+// DeleteExpired keeps tombstones, they hold deleted codes even after expiry.
+func (r *ShortenerRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, "DELETE FROM links WHERE expires_at <= $1 AND deleted_at IS NULL", now)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *ShortenerRepository) UpdateLink(ctx context.Context, link *model.Link) error {
+	tag, err := r.pool.Exec(ctx,
+		"UPDATE links SET original_url = $2, redirect_type = $3, expires_at = $4 WHERE short_url = $1 AND deleted_at IS NULL",
+		link.ShortURL, link.OriginalURL, link.RedirectType, link.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrLinkNotFound
+	}
+	r.notify(ctx, link.ShortURL)
+	return nil
+}
+
+// DeleteLink leaves the row as a tombstone so the code is never handed out again.
+func (r *ShortenerRepository) DeleteLink(ctx context.Context, shortURL string, now time.Time) error {
+	tag, err := r.pool.Exec(ctx, "UPDATE links SET deleted_at = $2 WHERE short_url = $1 AND deleted_at IS NULL", shortURL, now)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrLinkNotFound
+	}
+	r.notify(ctx, shortURL)
+	return nil
+}
+
+func (r *ShortenerRepository) ListLinks(ctx context.Context, query model.ListQuery) ([]model.Link, error) {
+	var sb strings.Builder
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	sb.WriteString("SELECT " + linkColumns + " FROM links WHERE deleted_at IS NULL")
+	if query.Owner != "" {
+		sb.WriteString(" AND owner = " + arg(query.Owner))
+	}
+	if query.Host != "" {
+		sb.WriteString(" AND " + hostExpr + " = " + arg(strings.ToLower(query.Host)))
+	}
+	if query.CreatedAfter != nil {
+		sb.WriteString(" AND created_at >= " + arg(*query.CreatedAfter))
+	}
+	if query.CreatedBefore != nil {
+		sb.WriteString(" AND created_at < " + arg(*query.CreatedBefore))
+	}
+
+	order, cmp := "ASC", ">"
+	if query.Desc {
+		order, cmp = "DESC", "<"
+	}
+	if query.After != nil {
+		sb.WriteString(" AND (created_at, id) " + cmp + " (" + arg(query.After.CreatedAt) + ", " + arg(query.After.ID) + ")")
+	}
+	sb.WriteString(" ORDER BY created_at " + order + ", id " + order + " LIMIT " + arg(query.Limit))
+
+	rows, err := r.pool.Query(ctx, sb.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []model.Link{}
+	for rows.Next() {
+		var link model.Link
+		if err := rows.Scan(linkFields(&link)...); err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
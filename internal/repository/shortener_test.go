@@ -7,8 +7,18 @@ import (
 	"github.com/pashagolub/pgxmock/v4"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
+	"urlShortener/internal/model"
 )
 
+func linkRows(links ...model.Link) *pgxmock.Rows {
+	rows := pgxmock.NewRows([]string{"id", "short_url", "original_url", "redirect_type", "expires_at", "deleted_at", "owner", "created_at"})
+	for _, link := range links {
+		rows.AddRow(link.ID, link.ShortURL, link.OriginalURL, link.RedirectType, link.ExpiresAt, link.DeletedAt, link.Owner, link.CreatedAt)
+	}
+	return rows
+}
+
 func TestCreateShortURL(t *testing.T) {
 	mockPool, err := pgxmock.NewPool()
 	if err != nil {
@@ -18,20 +28,23 @@ func TestCreateShortURL(t *testing.T) {
 
 	repo := ShortenerRepository{pool: mockPool}
 
+	createdAt := time.Now()
+	link := &model.Link{ID: 1, ShortURL: "abc123", OriginalURL: "https://example.com", RedirectType: 301, Owner: "marketing", CreatedAt: createdAt}
+
 	// Случай, успешной записи данных
 	mockPool.ExpectExec("INSERT INTO links").
-		WithArgs(1, "abc123", "https://example.com").
+		WithArgs(1, "abc123", "https://example.com", 301, (*time.Time)(nil), "marketing", createdAt).
 		WillReturnResult(pgxmock.NewResult("INSERT", 1))
 
-	err = repo.CreateShortURL(context.Background(), 1, "abc123", "https://example.com")
+	err = repo.CreateShortURL(context.Background(), link)
 	assert.NoError(t, err)
 
 	// Случай, когда ошибка при выполнении запроса
 	mockPool.ExpectExec("INSERT INTO links").
-		WithArgs(1, "abc123", "https://example.com").
+		WithArgs(1, "abc123", "https://example.com", 301, (*time.Time)(nil), "marketing", createdAt).
 		WillReturnError(fmt.Errorf("database error"))
 
-	err = repo.CreateShortURL(context.Background(), 1, "abc123", "https://example.com")
+	err = repo.CreateShortURL(context.Background(), link)
 	assert.Error(t, err)
 }
 
@@ -45,16 +58,18 @@ func TestGetShortURL(t *testing.T) {
 	repo := ShortenerRepository{pool: mockPool}
 
 	// Случай, когда данные успешно получены
-	mockPool.ExpectQuery("SELECT original_url FROM links WHERE short_url").
+	mockPool.ExpectQuery("SELECT (.+) FROM links WHERE short_url").
 		WithArgs("abc123").
-		WillReturnRows(pgxmock.NewRows([]string{"original_url"}).AddRow("https://example.com"))
+		WillReturnRows(linkRows(model.Link{ID: 1, ShortURL: "abc123", OriginalURL: "https://example.com", RedirectType: 307, Owner: "marketing"}))
 
-	originalURL, err := repo.GetOriginalURL(context.Background(), "abc123")
+	link, err := repo.GetOriginalURL(context.Background(), "abc123")
 	assert.NoError(t, err)
-	assert.Equal(t, "https://example.com", originalURL)
+	assert.Equal(t, "https://example.com", link.OriginalURL)
+	assert.Equal(t, 307, link.RedirectType)
+	assert.Equal(t, "marketing", link.Owner)
 
 	// Случай, когда URL не найден
-	mockPool.ExpectQuery("SELECT original_url FROM links WHERE short_url").
+	mockPool.ExpectQuery("SELECT (.+) FROM links WHERE short_url").
 		WithArgs("linkNotFound").
 		WillReturnError(ErrLinkNotFound)
 
@@ -62,7 +77,7 @@ func TestGetShortURL(t *testing.T) {
 	assert.ErrorIs(t, err, ErrLinkNotFound)
 
 	// Случай, когда ошибка при выполнении запроса
-	mockPool.ExpectQuery("SELECT original_url FROM links WHERE short_url").
+	mockPool.ExpectQuery("SELECT (.+) FROM links WHERE short_url").
 		WithArgs("abc123").
 		WillReturnError(fmt.Errorf("database error"))
 
@@ -82,20 +97,20 @@ func TestCheckDublicate(t *testing.T) {
 
 	// Ситуация, когда дубликат найден
 	mockPool.ExpectQuery("SELECT short_url FROM links WHERE original_url").
-		WithArgs("https://example.com").WillReturnRows(pgxmock.NewRows([]string{"original_url"}).
+		WithArgs("https://example.com", "").WillReturnRows(pgxmock.NewRows([]string{"original_url"}).
 		AddRow("abc123"))
 
-	dublicateURL, err := repo.CheckDublicate(context.Background(), "https://example.com")
+	dublicateURL, err := repo.CheckDublicate(context.Background(), "https://example.com", "")
 	assert.NoError(t, err)
 	assert.Equal(t, "abc123", dublicateURL)
 
 	// Ситуация, когда дубликат не найден
 
 	mockPool.ExpectQuery("SELECT short_url FROM links WHERE original_url").
-		WithArgs("https://example.com").
+		WithArgs("https://example.com", "").
 		WillReturnError(pgx.ErrNoRows)
 
-	_, err = repo.CheckDublicate(context.Background(), "https://example.com")
+	_, err = repo.CheckDublicate(context.Background(), "https://example.com", "")
 	assert.ErrorIs(t, err, ErrLinkNotFound)
 }
 
@@ -109,7 +124,7 @@ func TestGetNextID(t *testing.T) {
 	repo := ShortenerRepository{pool: mockpool}
 
 	// Случай, когда ожидаем успешное получение нового ID
-	mockpool.ExpectQuery("SELECT COALESCE\\(MAX\\(id\\), 0\\) \\+ 1 FROM links").
+	mockpool.ExpectQuery("SELECT nextval\\('links_id_seq'\\)").
 		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(2))
 
 	id, err := repo.GetNextID(context.Background())
@@ -117,10 +132,251 @@ func TestGetNextID(t *testing.T) {
 	assert.Equal(t, 2, id)
 
 	// Случай, когда неудалось получить ID
-	mockpool.ExpectQuery("SELECT COALESCE\\(MAX\\(id\\), 0\\) \\+ 1 FROM links").
+	mockpool.ExpectQuery("SELECT nextval\\('links_id_seq'\\)").
 		WillReturnError(fmt.Errorf("database error"))
 
 	id, err = repo.GetNextID(context.Background())
 	assert.Error(t, err)
 	assert.Equal(t, 0, id)
 }
+
+func TestShortURLExists(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock pool: %v", err)
+	}
+	defer mockPool.Close()
+
+	repo := ShortenerRepository{pool: mockPool}
+
+	// Случай, когда короткая ссылка уже занята
+	mockPool.ExpectQuery("SELECT EXISTS").
+		WithArgs("spring-sale").
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+
+	exists, err := repo.ShortURLExists(context.Background(), "spring-sale")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	// Случай, когда ошибка при выполнении запроса
+	mockPool.ExpectQuery("SELECT EXISTS").
+		WithArgs("spring-sale").
+		WillReturnError(fmt.Errorf("database error"))
+
+	_, err = repo.ShortURLExists(context.Background(), "spring-sale")
+	assert.Error(t, err)
+}
+
+func TestDeleteExpired(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock pool: %v", err)
+	}
+	defer mockPool.Close()
+
+	repo := ShortenerRepository{pool: mockPool}
+	now := time.Now()
+
+	// Случай, когда просроченные ссылки удалены
+	mockPool.ExpectExec("DELETE FROM links WHERE expires_at").
+		WithArgs(now).
+		WillReturnResult(pgxmock.NewResult("DELETE", 3))
+
+	deleted, err := repo.DeleteExpired(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+
+	// Случай, когда ошибка при выполнении запроса
+	mockPool.ExpectExec("DELETE FROM links WHERE expires_at").
+		WithArgs(now).
+		WillReturnError(fmt.Errorf("database error"))
+
+	_, err = repo.DeleteExpired(context.Background(), now)
+	assert.Error(t, err)
+}
+
+func TestUpdateLink(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock pool: %v", err)
+	}
+	defer mockPool.Close()
+
+	repo := ShortenerRepository{pool: mockPool}
+	link := &model.Link{ShortURL: "abc123", OriginalURL: "https://example.org", RedirectType: 301}
+
+	// Случай, успешного обновления ссылки
+	mockPool.ExpectExec("UPDATE links SET original_url").
+		WithArgs("abc123", "https://example.org", 301, (*time.Time)(nil)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	err = repo.UpdateLink(context.Background(), link)
+	assert.NoError(t, err)
+
+	// Случай, когда ссылка не найдена или удалена
+	mockPool.ExpectExec("UPDATE links SET original_url").
+		WithArgs("abc123", "https://example.org", 301, (*time.Time)(nil)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	err = repo.UpdateLink(context.Background(), link)
+	assert.ErrorIs(t, err, ErrLinkNotFound)
+}
+
+func TestDeleteLink(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock pool: %v", err)
+	}
+	defer mockPool.Close()
+
+	repo := ShortenerRepository{pool: mockPool}
+	now := time.Now()
+
+	// Случай, когда ссылка помечена удалённой
+	mockPool.ExpectExec("UPDATE links SET deleted_at").
+		WithArgs("abc123", now).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	err = repo.DeleteLink(context.Background(), "abc123", now)
+	assert.NoError(t, err)
+
+	// Случай, когда ссылка уже удалена
+	mockPool.ExpectExec("UPDATE links SET deleted_at").
+		WithArgs("abc123", now).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	err = repo.DeleteLink(context.Background(), "abc123", now)
+	assert.ErrorIs(t, err, ErrLinkNotFound)
+}
+
+func TestListLinks(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock pool: %v", err)
+	}
+	defer mockPool.Close()
+
+	repo := ShortenerRepository{pool: mockPool}
+	cursor := &model.LinkCursor{CreatedAt: time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), ID: 10}
+
+	// Случай, когда фильтры и курсор попадают в запрос
+	mockPool.ExpectQuery(`SELECT (.+) FROM links WHERE deleted_at IS NULL AND owner = \$1 AND (.+) = \$2 `+
+		`AND \(created_at, id\) < \(\$3, \$4\) ORDER BY created_at DESC, id DESC LIMIT \$5`).
+		WithArgs("marketing", "example.com", cursor.CreatedAt, 10, 3).
+		WillReturnRows(linkRows(
+			model.Link{ID: 9, ShortURL: "B", OriginalURL: "https://example.com/b", Owner: "marketing"},
+			model.Link{ID: 8, ShortURL: "A", OriginalURL: "https://example.com/a", Owner: "marketing"},
+		))
+
+	links, err := repo.ListLinks(context.Background(), model.ListQuery{
+		Owner: "marketing",
+		Host:  "Example.com",
+		Desc:  true,
+		After: cursor,
+		Limit: 3,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, links, 2)
+	assert.Equal(t, "B", links[0].ShortURL)
+
+	// Случай, когда ошибка при выполнении запроса
+	mockPool.ExpectQuery("SELECT (.+) FROM links WHERE deleted_at IS NULL").
+		WithArgs(50).
+		WillReturnError(fmt.Errorf("database error"))
+
+	_, err = repo.ListLinks(context.Background(), model.ListQuery{Limit: 50})
+	assert.Error(t, err)
+}
+
+func TestCreateShortURLs(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock pool: %v", err)
+	}
+	defer mockPool.Close()
+
+	repo := ShortenerRepository{pool: mockPool}
+	createdAt := time.Now()
+	links := []*model.Link{
+		{ID: 1, ShortURL: "b", OriginalURL: "https://example.com/1", CreatedAt: createdAt},
+		{ID: 2, ShortURL: "sale", OriginalURL: "https://example.com/2", CreatedAt: createdAt},
+	}
+
+	args := []any{
+		1, "b", "https://example.com/1", 0, (*time.Time)(nil), "", createdAt,
+		2, "sale", "https://example.com/2", 0, (*time.Time)(nil), "", createdAt,
+	}
+
+	// Случай, когда один из кодов уже занят
+	mockPool.ExpectBegin()
+	mockPool.ExpectQuery(`INSERT INTO links (.+) VALUES \(\$1, (.+)\), \(\$8, (.+)\) ON CONFLICT \(short_url\) DO NOTHING RETURNING id`).
+		WithArgs(args...).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(1))
+	mockPool.ExpectCommit()
+
+	taken, err := repo.CreateShortURLs(context.Background(), links)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2}, taken)
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+
+	// Случай, когда ошибка откатывает транзакцию
+	mockPool.ExpectBegin()
+	mockPool.ExpectQuery("INSERT INTO links").
+		WithArgs(args...).
+		WillReturnError(fmt.Errorf("database error"))
+	mockPool.ExpectRollback()
+
+	_, err = repo.CreateShortURLs(context.Background(), links)
+	assert.Error(t, err)
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestGetNextIDs(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock pool: %v", err)
+	}
+	defer mockPool.Close()
+
+	repo := ShortenerRepository{pool: mockPool}
+
+	mockPool.ExpectQuery(`SELECT nextval\('links_id_seq'\) FROM generate_series`).
+		WithArgs(3).
+		WillReturnRows(pgxmock.NewRows([]string{"nextval"}).AddRow(7).AddRow(8).AddRow(9))
+
+	ids, err := repo.GetNextIDs(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{7, 8, 9}, ids)
+}
+
+func TestGetOriginalURLs(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock pool: %v", err)
+	}
+	defer mockPool.Close()
+
+	repo := ShortenerRepository{pool: mockPool}
+	codes := []string{"abc", "def", "missing"}
+
+	// Случай, когда найдена часть кодов
+	mockPool.ExpectQuery(`SELECT (.+) FROM links WHERE short_url = ANY\(\$1\)`).
+		WithArgs(codes).
+		WillReturnRows(linkRows(
+			model.Link{ID: 1, ShortURL: "abc", OriginalURL: "https://example.com/a"},
+			model.Link{ID: 2, ShortURL: "def", OriginalURL: "https://example.com/d"},
+		))
+
+	links, err := repo.GetOriginalURLs(context.Background(), codes)
+	assert.NoError(t, err)
+	assert.Len(t, links, 2)
+	assert.Equal(t, "https://example.com/d", links["def"].OriginalURL)
+
+	// Случай, когда ошибка при выполнении запроса
+	mockPool.ExpectQuery("SELECT (.+) FROM links WHERE short_url = ANY").
+		WithArgs(codes).
+		WillReturnError(fmt.Errorf("database error"))
+
+	_, err = repo.GetOriginalURLs(context.Background(), codes)
+	assert.Error(t, err)
+}
@@ -0,0 +1,326 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+	"urlShortener/internal/initialize"
+	"urlShortener/internal/model"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// sqliteBulkInsertRows keeps a multi-row INSERT of links below SQLite's 32766 parameter limit.
+const sqliteBulkInsertRows = 1000
+
+func init() {
+	// SQLite has no regular expressions, url_host gives ListQuery.Host
+	// filtering the same meaning as linkHost does for the memory storage.
+	sqlite.MustRegisterDeterministicScalarFunction("url_host", 1,
+		func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+			originalURL, _ := args[0].(string)
+			return strings.ToLower(linkHost(originalURL)), nil
+		})
+}
+
+// SQLiteRepository keeps links in a local SQLite file, for deployments
+// that need durable storage without a Postgres server.
+type SQLiteRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+func NewSQLiteRepository(dbInstance *initialize.DB, logger *zap.Logger) (*SQLiteRepository, error) {
+	if err := dbInstance.RunMigrations(logger); err != nil {
+		return nil, err
+	}
+
+	return &SQLiteRepository{
+		db:     dbInstance.SQLite,
+		logger: logger,
+	}, nil
+}
+
+// SQLite compares timestamps as text, so every time is stored in UTC.
+func utc(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
+
+func (r *SQLiteRepository) CreateShortURL(ctx context.Context, link *model.Link) error {
+	_, err := r.db.ExecContext(ctx,
+		"INSERT INTO links (id, short_url, original_url, redirect_type, expires_at, owner, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		link.ID, link.ShortURL, link.OriginalURL, link.RedirectType, utc(link.ExpiresAt), link.Owner, link.CreatedAt.UTC())
+	if err != nil {
+		if isSQLiteShortURLConflict(err) {
+			return ErrShortURLExists
+		}
+		return err
+	}
+	return nil
+}
+
+// isSQLiteShortURLConflict reports whether err is the unique index violation on links.short_url.
+func isSQLiteShortURLConflict(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE &&
+		strings.Contains(sqliteErr.Error(), "links.short_url")
+}
+
+// CreateShortURLs inserts links with multi-row INSERTs in one transaction.
+// Links whose short URL is already taken are skipped, their IDs are returned.
+func (r *SQLiteRepository) CreateShortURLs(ctx context.Context, links []*model.Link) ([]int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	inserted := make(map[int]struct{}, len(links))
+	for start := 0; start < len(links); start += sqliteBulkInsertRows {
+		chunk := links[start:min(start+sqliteBulkInsertRows, len(links))]
+
+		var sb strings.Builder
+		sb.WriteString("INSERT INTO links (id, short_url, original_url, redirect_type, expires_at, owner, created_at) VALUES ")
+		args := make([]any, 0, len(chunk)*7)
+		for i, link := range chunk {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString("(?, ?, ?, ?, ?, ?, ?)")
+			args = append(args, link.ID, link.ShortURL, link.OriginalURL, link.RedirectType, utc(link.ExpiresAt), link.Owner, link.CreatedAt.UTC())
+		}
+		sb.WriteString(" ON CONFLICT (short_url) DO NOTHING RETURNING id")
+
+		ids, err := queryInts(ctx, tx, sb.String(), args...)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			inserted[id] = struct{}{}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	var taken []int
+	for _, link := range links {
+		if _, ok := inserted[link.ID]; !ok {
+			taken = append(taken, link.ID)
+		}
+	}
+	return taken, nil
+}
+
+func (r *SQLiteRepository) GetOriginalURL(ctx context.Context, shortURL string) (*model.Link, error) {
+	link := &model.Link{ShortURL: shortURL}
+	err := r.db.QueryRowContext(ctx, "SELECT "+linkColumns+" FROM links WHERE short_url = ?", shortURL).
+		Scan(linkFields(link)...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrLinkNotFound
+		}
+		return nil, err
+	}
+	return link, nil
+}
+
+// GetOriginalURLs looks up many codes in one query per chunk. Codes that do
+// not exist are missing from the result.
+func (r *SQLiteRepository) GetOriginalURLs(ctx context.Context, shortURLs []string) (map[string]model.Link, error) {
+	links := make(map[string]model.Link, len(shortURLs))
+	for start := 0; start < len(shortURLs); start += sqliteBulkInsertRows {
+		chunk := shortURLs[start:min(start+sqliteBulkInsertRows, len(shortURLs))]
+
+		args := make([]any, len(chunk))
+		for i, shortURL := range chunk {
+			args[i] = shortURL
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(chunk)), ", ")
+
+		rows, err := r.db.QueryContext(ctx, "SELECT "+linkColumns+" FROM links WHERE short_url IN ("+placeholders+")", args...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var link model.Link
+			if err := rows.Scan(linkFields(&link)...); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			links[link.ShortURL] = link
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return links, nil
+}
+
+func (r *SQLiteRepository) CheckDublicate(ctx context.Context, originalURL string, owner string) (string, error) {
+	var dublicateURL string
+	err := r.db.QueryRowContext(ctx,
+		"SELECT short_url FROM links WHERE original_url = ? AND owner = ? AND expires_at IS NULL AND deleted_at IS NULL ORDER BY id LIMIT 1",
+		originalURL, owner).Scan(&dublicateURL)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrLinkNotFound
+		}
+		return "", err
+	}
+	return dublicateURL, nil
+}
+
+// GetNextID reserves an ID from the links_id_seq counter row. The UPDATE
+// takes SQLite's write lock, so concurrent callers never get the same one.
+func (r *SQLiteRepository) GetNextID(ctx context.Context) (int, error) {
+	ids, err := r.GetNextIDs(ctx, 1)
+	if err != nil {
+		return 0, err
+	}
+	return ids[0], nil
+}
+
+func (r *SQLiteRepository) GetNextIDs(ctx context.Context, n int) ([]int, error) {
+	var last int
+	err := r.db.QueryRowContext(ctx, "UPDATE links_id_seq SET last_value = last_value + ? RETURNING last_value", n).Scan(&last)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, n)
+	for i := range ids {
+		ids[i] = last - n + 1 + i
+	}
+	return ids, nil
+}
+
+func (r *SQLiteRepository) ShortURLExists(ctx context.Context, shortURL string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM links WHERE short_url = ?)", shortURL).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+// DeleteExpired keeps tombstones, they hold deleted codes even after expiry.
+func (r *SQLiteRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM links WHERE expires_at <= ? AND deleted_at IS NULL", now.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *SQLiteRepository) UpdateLink(ctx context.Context, link *model.Link) error {
+	res, err := r.db.ExecContext(ctx,
+		"UPDATE links SET original_url = ?, redirect_type = ?, expires_at = ? WHERE short_url = ? AND deleted_at IS NULL",
+		link.OriginalURL, link.RedirectType, utc(link.ExpiresAt), link.ShortURL)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res)
+}
+
+// DeleteLink leaves the row as a tombstone so the code is never handed out again.
+func (r *SQLiteRepository) DeleteLink(ctx context.Context, shortURL string, now time.Time) error {
+	res, err := r.db.ExecContext(ctx, "UPDATE links SET deleted_at = ? WHERE short_url = ? AND deleted_at IS NULL", now.UTC(), shortURL)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res)
+}
+
+func (r *SQLiteRepository) ListLinks(ctx context.Context, query model.ListQuery) ([]model.Link, error) {
+	var sb strings.Builder
+	var args []any
+
+	sb.WriteString("SELECT " + linkColumns + " FROM links WHERE deleted_at IS NULL")
+	if query.Owner != "" {
+		sb.WriteString(" AND owner = ?")
+		args = append(args, query.Owner)
+	}
+	if query.Host != "" {
+		sb.WriteString(" AND url_host(original_url) = ?")
+		args = append(args, strings.ToLower(query.Host))
+	}
+	if query.CreatedAfter != nil {
+		sb.WriteString(" AND created_at >= ?")
+		args = append(args, query.CreatedAfter.UTC())
+	}
+	if query.CreatedBefore != nil {
+		sb.WriteString(" AND created_at < ?")
+		args = append(args, query.CreatedBefore.UTC())
+	}
+
+	order, cmp := "ASC", ">"
+	if query.Desc {
+		order, cmp = "DESC", "<"
+	}
+	if query.After != nil {
+		sb.WriteString(" AND (created_at, id) " + cmp + " (?, ?)")
+		args = append(args, query.After.CreatedAt.UTC(), query.After.ID)
+	}
+	sb.WriteString(" ORDER BY created_at " + order + ", id " + order + " LIMIT ?")
+	args = append(args, query.Limit)
+
+	rows, err := r.db.QueryContext(ctx, sb.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []model.Link{}
+	for rows.Next() {
+		var link model.Link
+		if err := rows.Scan(linkFields(&link)...); err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+func queryInts(ctx context.Context, tx *sql.Tx, query string, args ...any) ([]int, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func requireAffected(res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrLinkNotFound
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"urlShortener/internal/initialize"
+	"urlShortener/internal/model"
+)
+
+func newSQLiteRepository(t *testing.T) *SQLiteRepository {
+	t.Helper()
+
+	// Миграции ищутся относительно корня репозитория, как при запуске сервиса
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(filepath.Join(wd, "..", "..")))
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	db, err := initialize.NewSQLiteClient(context.Background(), filepath.Join(t.TempDir(), "links.db"))
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+
+	repo, err := NewSQLiteRepository(db, zap.NewNop())
+	require.NoError(t, err)
+	return repo
+}
+
+func TestSQLiteRepository(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+	// Время не в UTC: сравнение меток в SQLite должно от этого не зависеть
+	now := time.Now().In(time.FixedZone("MSK", 3*60*60)).Truncate(time.Microsecond)
+	past := now.Add(-time.Hour)
+
+	ids, err := repo.GetNextIDs(ctx, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, ids)
+	id, err := repo.GetNextID(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 4, id)
+
+	require.NoError(t, repo.CreateShortURL(ctx, &model.Link{ID: 1, ShortURL: "b", OriginalURL: "https://Example.com/1", Owner: "marketing", CreatedAt: now}))
+	require.NoError(t, repo.CreateShortURL(ctx, &model.Link{ID: 2, ShortURL: "c", OriginalURL: "https://other.org/2", CreatedAt: now.Add(time.Second)}))
+	require.NoError(t, repo.CreateShortURL(ctx, &model.Link{ID: 3, ShortURL: "d", OriginalURL: "https://example.com/3", ExpiresAt: &past, CreatedAt: now}))
+	assert.Error(t, repo.CreateShortURL(ctx, &model.Link{ID: 4, ShortURL: "b", OriginalURL: "https://example.com/4", CreatedAt: now}))
+
+	// Занятые короткие ссылки пропускаются, их ID возвращаются
+	taken, err := repo.CreateShortURLs(ctx, []*model.Link{
+		{ID: 5, ShortURL: "e", OriginalURL: "https://example.com/5", CreatedAt: now.Add(2 * time.Second)},
+		{ID: 6, ShortURL: "c", OriginalURL: "https://example.com/6", CreatedAt: now},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{6}, taken)
+
+	link, err := repo.GetOriginalURL(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, "marketing", link.Owner)
+	assert.True(t, now.Equal(link.CreatedAt))
+	assert.Nil(t, link.ExpiresAt)
+
+	_, err = repo.GetOriginalURL(ctx, "missing")
+	assert.ErrorIs(t, err, ErrLinkNotFound)
+
+	links, err := repo.GetOriginalURLs(ctx, []string{"b", "e", "missing"})
+	require.NoError(t, err)
+	assert.Len(t, links, 2)
+	assert.Equal(t, "https://example.com/5", links["e"].OriginalURL)
+
+	short, err := repo.CheckDublicate(ctx, "https://Example.com/1", "marketing")
+	require.NoError(t, err)
+	assert.Equal(t, "b", short)
+	_, err = repo.CheckDublicate(ctx, "https://Example.com/1", "")
+	assert.ErrorIs(t, err, ErrLinkNotFound)
+
+	exists, err := repo.ShortURLExists(ctx, "d")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	require.NoError(t, repo.UpdateLink(ctx, &model.Link{ShortURL: "c", OriginalURL: "https://other.org/updated", RedirectType: 301}))
+	assert.ErrorIs(t, repo.UpdateLink(ctx, &model.Link{ShortURL: "missing"}), ErrLinkNotFound)
+	require.NoError(t, repo.DeleteLink(ctx, "e", now))
+	assert.ErrorIs(t, repo.DeleteLink(ctx, "e", now), ErrLinkNotFound)
+
+	deleted, err := repo.DeleteExpired(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	// Удалённые и истёкшие ссылки не попадают в список, фильтр по хосту не зависит от регистра
+	listed, err := repo.ListLinks(ctx, model.ListQuery{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+	assert.Equal(t, "b", listed[0].ShortURL)
+	assert.Equal(t, "c", listed[1].ShortURL)
+	assert.Equal(t, 301, listed[1].RedirectType)
+
+	listed, err = repo.ListLinks(ctx, model.ListQuery{Host: "EXAMPLE.com", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, "b", listed[0].ShortURL)
+
+	listed, err = repo.ListLinks(ctx, model.ListQuery{
+		Desc:  true,
+		After: &model.LinkCursor{CreatedAt: now.Add(time.Second), ID: 2},
+		Limit: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, "b", listed[0].ShortURL)
+}
+
+// Тест: общая цепочка schema/ откатывается на SQLite до нуля и накатывается снова
+func TestSQLiteMigrationsDownAndUp(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+
+	require.NoError(t, goose.DownTo(repo.db, "schema", 0))
+	require.NoError(t, goose.Up(repo.db, "schema"))
+
+	ids, err := repo.GetNextIDs(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, ids)
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	expiresAt := now.Add(time.Hour)
+	require.NoError(t, repo.CreateShortURL(ctx, &model.Link{ID: 1, ShortURL: "b", OriginalURL: "https://example.com", Owner: "marketing", ExpiresAt: &expiresAt, CreatedAt: now}))
+
+	link, err := repo.GetOriginalURL(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, "marketing", link.Owner)
+	assert.True(t, now.Equal(link.CreatedAt))
+	require.NotNil(t, link.ExpiresAt)
+	assert.True(t, expiresAt.Equal(*link.ExpiresAt))
+}
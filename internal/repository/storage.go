@@ -2,86 +2,335 @@ package repository
 
 import (
 	"context"
-	"errors"
 	"go.uber.org/zap"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
+	"urlShortener/internal/model"
 )
 
 type URLStorage struct {
-	mu      sync.Mutex
-	storage map[int]string // ID -> Original URL
-	shorts  map[string]int // Short URL -> ID
-	logger  *zap.Logger
+	mu        sync.RWMutex
+	lastID    atomic.Int64
+	storage   map[int]model.Link // ID -> Link
+	shorts    map[string]int     // Short URL -> ID
+	originals map[string][]int   // Owner + Original URL -> IDs in ascending order, permanent links only
+	logger    *zap.Logger
 }
 
 func NewURLStorage(logger *zap.Logger) *URLStorage {
 	return &URLStorage{
-		storage: make(map[int]string),
-		shorts:  make(map[string]int),
-		logger:  logger,
+		storage:   make(map[int]model.Link),
+		shorts:    make(map[string]int),
+		originals: make(map[string][]int),
+		logger:    logger,
 	}
 }
 
-func (s *URLStorage) CreateShortURL(ctx context.Context, id int, shortURL string, originalURL string) error {
+func (s *URLStorage) CreateShortURL(ctx context.Context, link *model.Link) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.shorts[shortURL]; exists {
-		s.logger.Error("short URL already exists", zap.Int("id", id), zap.String("short_url", shortURL))
-		return errors.New("short URL already exists")
+	if _, exists := s.shorts[link.ShortURL]; exists {
+		s.logger.Error("short URL already exists", zap.Int("id", link.ID), zap.String("short_url", link.ShortURL))
+		return ErrShortURLExists
 	}
 
-	s.storage[id] = originalURL
-	s.shorts[shortURL] = id
-	s.logger.Info("short URL created", zap.Int("id", id), zap.String("original_url", originalURL), zap.String("short_url", shortURL))
+	s.storage[link.ID] = *link
+	s.shorts[link.ShortURL] = link.ID
+	s.rememberOriginal(*link)
+	s.bumpLastID(int64(link.ID))
+	s.logger.Info("short URL created", zap.Int("id", link.ID), zap.String("original_url", link.OriginalURL), zap.String("short_url", link.ShortURL))
 	return nil
 }
 
-func (s *URLStorage) GetOriginalURL(ctx context.Context, shortURL string) (string, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+func (s *URLStorage) GetOriginalURL(ctx context.Context, shortURL string) (*model.Link, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
 	id, exists := s.shorts[shortURL]
 	if !exists {
 		s.logger.Error("short URL not found", zap.String("short_url", shortURL))
-		return "", ErrLinkNotFound
+		return nil, ErrLinkNotFound
 	}
 
-	originalURL, exists := s.storage[id]
+	link, exists := s.storage[id]
 	if !exists {
 		s.logger.Error("short URL not found", zap.String("short_url", shortURL))
-		return "", ErrLinkNotFound
+		return nil, ErrLinkNotFound
 	}
 
-	s.logger.Info("Successfully retrieved short URL", zap.String("original_url", originalURL), zap.String("short_url", shortURL))
-	return originalURL, nil
+	s.logger.Info("Successfully retrieved short URL", zap.String("original_url", link.OriginalURL), zap.String("short_url", shortURL))
+	return &link, nil
 }
 
-func (s *URLStorage) CheckDublicate(ctx context.Context, originalURL string) (string, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+func (s *URLStorage) GetOriginalURLs(ctx context.Context, shortURLs []string) (map[string]model.Link, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	for shortURL, storedID := range s.shorts {
-		if storedURL, exists := s.storage[storedID]; exists && storedURL == originalURL {
-			s.logger.Info("Dublicate short URL found", zap.String("original_url", originalURL))
-			return shortURL, nil
+	links := make(map[string]model.Link, len(shortURLs))
+	for _, shortURL := range shortURLs {
+		if id, exists := s.shorts[shortURL]; exists {
+			links[shortURL] = s.storage[id]
 		}
 	}
+	return links, nil
+}
+
+func (s *URLStorage) CheckDublicate(ctx context.Context, originalURL string, owner string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// The lowest ID wins, as in the Postgres query.
+	if ids := s.originals[originalKey(owner, originalURL)]; len(ids) > 0 {
+		s.logger.Info("Dublicate short URL found", zap.String("original_url", originalURL))
+		return s.storage[ids[0]].ShortURL, nil
+	}
 	s.logger.Info("Dublicate short URL not found", zap.String("original_url", originalURL))
 	return "", ErrLinkNotFound
 }
 
+// CreateShortURLs stores links under a single lock. Links whose short URL
+// is already taken are skipped, their IDs are returned.
+func (s *URLStorage) CreateShortURLs(ctx context.Context, links []*model.Link) ([]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var taken []int
+	for _, link := range links {
+		if _, exists := s.shorts[link.ShortURL]; exists {
+			taken = append(taken, link.ID)
+			continue
+		}
+		s.storage[link.ID] = *link
+		s.shorts[link.ShortURL] = link.ID
+		s.rememberOriginal(*link)
+		s.bumpLastID(int64(link.ID))
+	}
+	s.logger.Info("short URLs created", zap.Int("count", len(links)-len(taken)))
+	return taken, nil
+}
+
+// GetNextID hands out IDs from an atomic counter, so concurrent callers never get the same one.
 func (s *URLStorage) GetNextID(ctx context.Context) (int, error) {
+	return int(s.lastID.Add(1)), nil
+}
+
+func (s *URLStorage) GetNextIDs(ctx context.Context, n int) ([]int, error) {
+	last := int(s.lastID.Add(int64(n)))
+	ids := make([]int, n)
+	for i := range ids {
+		ids[i] = last - n + 1 + i
+	}
+	return ids, nil
+}
+
+// bumpLastID keeps the counter ahead of IDs that were stored without GetNextID.
+func (s *URLStorage) bumpLastID(id int64) {
+	for {
+		last := s.lastID.Load()
+		if id <= last || s.lastID.CompareAndSwap(last, id) {
+			return
+		}
+	}
+}
+
+func (s *URLStorage) ShortURLExists(ctx context.Context, shortURL string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, exists := s.shorts[shortURL]
+	return exists, nil
+}
+
+// live reports whether shortURL is stored and not deleted.
+func (s *URLStorage) live(shortURL string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	id, exists := s.shorts[shortURL]
+	return exists && s.storage[id].DeletedAt == nil
+}
+
+// hasExpired reports whether DeleteExpired would remove anything at now.
+func (s *URLStorage) hasExpired(now time.Time) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, link := range s.storage {
+		if link.DeletedAt == nil && link.Expired(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteExpired keeps tombstones, they hold deleted codes even after expiry.
+func (s *URLStorage) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	for shortURL, id := range s.shorts {
+		if link := s.storage[id]; link.DeletedAt == nil && link.Expired(now) {
+			delete(s.shorts, shortURL)
+			delete(s.storage, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (s *URLStorage) UpdateLink(ctx context.Context, link *model.Link) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, exists := s.shorts[link.ShortURL]
+	if !exists || s.storage[id].DeletedAt != nil {
+		return ErrLinkNotFound
+	}
+
+	s.forgetOriginal(s.storage[id])
+	updated := s.storage[id]
+	updated.OriginalURL = link.OriginalURL
+	updated.RedirectType = link.RedirectType
+	updated.ExpiresAt = link.ExpiresAt
+	s.storage[id] = updated
+	s.rememberOriginal(updated)
+
+	s.logger.Info("short URL updated", zap.String("original_url", updated.OriginalURL), zap.String("short_url", updated.ShortURL))
+	return nil
+}
+
+// DeleteLink leaves the link as a tombstone so the code is never handed out again.
+func (s *URLStorage) DeleteLink(ctx context.Context, shortURL string, now time.Time) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Find the highest ID and return the next one
-	var maxID int
-	for id := range s.storage {
-		if id > maxID {
-			maxID = id
+	id, exists := s.shorts[shortURL]
+	if !exists || s.storage[id].DeletedAt != nil {
+		return ErrLinkNotFound
+	}
+
+	s.forgetOriginal(s.storage[id])
+	deleted := s.storage[id]
+	deleted.DeletedAt = &now
+	s.storage[id] = deleted
+
+	s.logger.Info("short URL deleted", zap.String("short_url", shortURL))
+	return nil
+}
+
+func (s *URLStorage) ListLinks(ctx context.Context, query model.ListQuery) ([]model.Link, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	links := []model.Link{}
+	for _, link := range s.storage {
+		if matchesQuery(link, query) {
+			links = append(links, link)
+		}
+	}
+
+	sort.Slice(links, func(i, j int) bool {
+		if query.Desc {
+			return linkBefore(links[j], links[i])
+		}
+		return linkBefore(links[i], links[j])
+	})
+
+	if len(links) > query.Limit {
+		links = links[:query.Limit]
+	}
+	return links, nil
+}
+
+// snapshot returns every stored link, tombstones included, in ID order.
+func (s *URLStorage) snapshot() []model.Link {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	links := make([]model.Link, 0, len(s.storage))
+	for _, link := range s.storage {
+		links = append(links, link)
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].ID < links[j].ID })
+	return links
+}
+
+func matchesQuery(link model.Link, query model.ListQuery) bool {
+	if link.DeletedAt != nil {
+		return false
+	}
+	if query.Owner != "" && link.Owner != query.Owner {
+		return false
+	}
+	if query.Host != "" && !strings.EqualFold(linkHost(link.OriginalURL), query.Host) {
+		return false
+	}
+	if query.CreatedAfter != nil && link.CreatedAt.Before(*query.CreatedAfter) {
+		return false
+	}
+	if query.CreatedBefore != nil && !link.CreatedAt.Before(*query.CreatedBefore) {
+		return false
+	}
+	if query.After != nil {
+		after := model.Link{CreatedAt: query.After.CreatedAt, ID: query.After.ID}
+		if query.Desc {
+			return linkBefore(link, after)
 		}
+		return linkBefore(after, link)
+	}
+	return true
+}
+
+// linkBefore orders links by (CreatedAt, ID), the same keyset the Postgres repository pages by.
+func linkBefore(a, b model.Link) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
 	}
+	return a.ID < b.ID
+}
+
+func linkHost(originalURL string) string {
+	u, err := url.Parse(originalURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+func (s *URLStorage) rememberOriginal(link model.Link) {
+	if link.ExpiresAt != nil || link.DeletedAt != nil {
+		return
+	}
+	key := originalKey(link.Owner, link.OriginalURL)
+	ids := s.originals[key]
+	i := sort.SearchInts(ids, link.ID)
+	if i < len(ids) && ids[i] == link.ID {
+		return
+	}
+	s.originals[key] = slices.Insert(ids, i, link.ID)
+}
+
+func (s *URLStorage) forgetOriginal(link model.Link) {
+	key := originalKey(link.Owner, link.OriginalURL)
+	ids := s.originals[key]
+	i := sort.SearchInts(ids, link.ID)
+	if i == len(ids) || ids[i] != link.ID {
+		return
+	}
+	if len(ids) == 1 {
+		delete(s.originals, key)
+		return
+	}
+	s.originals[key] = slices.Delete(ids, i, i+1)
+}
 
-	return maxID + 1, nil
+func originalKey(owner string, originalURL string) string {
+	return owner + "\x00" + originalURL
 }
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+	"urlShortener/internal/model"
+)
+
+func newFilledStorage(tb testing.TB, n int) *URLStorage {
+	storage := NewURLStorage(zap.NewNop())
+	for i := 1; i <= n; i++ {
+		err := storage.CreateShortURL(context.Background(), &model.Link{
+			ID:          i,
+			ShortURL:    fmt.Sprintf("s%d", i),
+			OriginalURL: fmt.Sprintf("https://example.com/%d", i),
+		})
+		if err != nil {
+			tb.Fatalf("failed to fill storage: %v", err)
+		}
+	}
+	return storage
+}
+
+func TestURLStorageCheckDublicate(t *testing.T) {
+	storage := newFilledStorage(t, 3)
+
+	// Повторная ссылка на тот же URL не подменяет первую
+	err := storage.CreateShortURL(context.Background(), &model.Link{ID: 4, ShortURL: "s4", OriginalURL: "https://example.com/2"})
+	assert.NoError(t, err)
+
+	shortURL, err := storage.CheckDublicate(context.Background(), "https://example.com/2", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "s2", shortURL)
+
+	_, err = storage.CheckDublicate(context.Background(), "https://example.com/404", "")
+	assert.ErrorIs(t, err, ErrLinkNotFound)
+}
+
+func TestURLStorageDeleteLeavesTombstone(t *testing.T) {
+	storage := newFilledStorage(t, 2)
+
+	err := storage.DeleteLink(context.Background(), "s1", time.Now())
+	assert.NoError(t, err)
+
+	// Код остаётся занятым и не переиспользуется
+	exists, err := storage.ShortURLExists(context.Background(), "s1")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	link, err := storage.GetOriginalURL(context.Background(), "s1")
+	assert.NoError(t, err)
+	assert.NotNil(t, link.DeletedAt)
+
+	_, err = storage.CheckDublicate(context.Background(), "https://example.com/1", "")
+	assert.ErrorIs(t, err, ErrLinkNotFound)
+
+	err = storage.DeleteLink(context.Background(), "s1", time.Now())
+	assert.ErrorIs(t, err, ErrLinkNotFound)
+
+	err = storage.UpdateLink(context.Background(), &model.Link{ShortURL: "s1", OriginalURL: "https://example.org"})
+	assert.ErrorIs(t, err, ErrLinkNotFound)
+}
+
+func TestURLStorageUpdateLink(t *testing.T) {
+	storage := newFilledStorage(t, 1)
+
+	err := storage.UpdateLink(context.Background(), &model.Link{ShortURL: "s1", OriginalURL: "https://example.org", RedirectType: 308})
+	assert.NoError(t, err)
+
+	link, err := storage.GetOriginalURL(context.Background(), "s1")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.org", link.OriginalURL)
+	assert.Equal(t, 308, link.RedirectType)
+
+	shortURL, err := storage.CheckDublicate(context.Background(), "https://example.org", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "s1", shortURL)
+
+	_, err = storage.CheckDublicate(context.Background(), "https://example.com/1", "")
+	assert.ErrorIs(t, err, ErrLinkNotFound)
+}
+
+func BenchmarkURLStorageCheckDublicate(b *testing.B) {
+	for _, n := range []int{1000, 100000, 1000000} {
+		storage := newFilledStorage(b, n)
+		target := fmt.Sprintf("https://example.com/%d", n/2)
+
+		b.Run(fmt.Sprintf("links=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := storage.CheckDublicate(context.Background(), target, ""); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestURLStorageCreateShortURLs(t *testing.T) {
+	storage := newFilledStorage(t, 2)
+
+	ids, err := storage.GetNextIDs(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 4, 5}, ids)
+
+	// Занятые коды пропускаются, остальные ссылки сохраняются
+	taken, err := storage.CreateShortURLs(context.Background(), []*model.Link{
+		{ID: 3, ShortURL: "s3", OriginalURL: "https://example.com/3"},
+		{ID: 4, ShortURL: "s1", OriginalURL: "https://example.com/4"},
+		{ID: 5, ShortURL: "s5", OriginalURL: "https://example.com/5"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{4}, taken)
+
+	link, err := storage.GetOriginalURL(context.Background(), "s5")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/5", link.OriginalURL)
+
+	link, err = storage.GetOriginalURL(context.Background(), "s1")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/1", link.OriginalURL)
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+	"strings"
+	"urlShortener/internal/model"
+	"urlShortener/internal/service"
+)
+
+const (
+	adminPrefix = "/api/admin"
+	linksPrefix = "/api/links"
+	statsSuffix = "/stats"
+	expandPath  = "/api/expand"
+
+	// APIKeyLocal is the fiber.Ctx local holding the *model.APIKey of an authenticated request.
+	APIKeyLocal = "api_key"
+
+	headerAPIKey     = "X-API-Key"
+	headerAdminToken = "X-Admin-Token"
+)
+
+type Authenticator interface {
+	Authenticate(ctx context.Context, rawKey string) (*model.APIKey, error)
+}
+
+// requireAPIKey guards every mutating request outside the admin API, the
+// per-owner link listing and link stats. Other reads, including redirects
+// and the bulk expand lookup sent as POST, stay public.
+func (s *Server) requireAPIKey(c fiber.Ctx) error {
+	if !needsAPIKey(c) {
+		return c.Next()
+	}
+
+	key, err := s.auth.Authenticate(c.Context(), apiKeyFromRequest(c))
+	if err != nil {
+		if errors.Is(err, service.ErrUnauthorized) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+		}
+		s.Logger.Error("error authenticating API key", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	c.Locals(APIKeyLocal, key)
+	return c.Next()
+}
+
+func needsAPIKey(c fiber.Ctx) bool {
+	if strings.HasPrefix(c.Path(), adminPrefix) || c.Path() == expandPath {
+		return false
+	}
+	switch c.Method() {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+		return strings.HasPrefix(c.Path(), linksPrefix) || strings.HasSuffix(c.Path(), statsSuffix)
+	}
+	return true
+}
+
+func (s *Server) requireAdminToken(c fiber.Ctx) error {
+	token := c.Get(headerAdminToken)
+	if s.adminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid or missing admin token"})
+	}
+	return c.Next()
+}
+
+// apiKeyFromRequest accepts either X-API-Key or an Authorization bearer token.
+func apiKeyFromRequest(c fiber.Ctx) string {
+	if key := c.Get(headerAPIKey); key != "" {
+		return key
+	}
+	if auth := c.Get(fiber.HeaderAuthorization); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+	return ""
+}
@@ -0,0 +1,132 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"net/http/httptest"
+	"testing"
+	"urlShortener/internal/model"
+	"urlShortener/internal/service"
+	mockService "urlShortener/mocks"
+)
+
+type stubController struct {
+	name string
+}
+
+func (s stubController) Register(router fiber.Router) {
+	router.Get("/:id", func(c fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	router.Get("/:id/stats", func(c fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	router.Post("/", func(c fiber.Ctx) error {
+		key, _ := c.Locals(APIKeyLocal).(*model.APIKey)
+		if key == nil {
+			return c.SendStatus(fiber.StatusOK)
+		}
+		return c.SendString(key.Name)
+	})
+	router.Delete("/:id", func(c fiber.Ctx) error { return c.SendStatus(fiber.StatusNoContent) })
+	router.Post("/api/expand", func(c fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	router.Post("/api/batch", func(c fiber.Ctx) error {
+		if !ChargeRate(c, fiber.Query[int](c, "items")) {
+			return RateLimitExceeded(c)
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+}
+
+func (s stubController) Name() string {
+	return s.name
+}
+
+func TestRequireAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockKeyService := mockService.NewMockKeyServiceInterface(ctrl)
+
+	server := NewServer(ServerConfig{
+		Controllers:      []Controller{stubController{}},
+		AdminControllers: []Controller{stubController{name: "/keys"}},
+		Auth:             mockKeyService,
+		AdminToken:       "admin-secret",
+		Logger:           zap.NewNop(),
+	})
+
+	// Тест: Чтение не требует ключа
+	t.Run("public read", func(t *testing.T) {
+		resp, err := server.app.Test(httptest.NewRequest("GET", "/abc123", nil), -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+
+	// Тест: Массовое разворачивание кодов — тоже чтение, хоть и через POST
+	t.Run("public expand", func(t *testing.T) {
+		resp, err := server.app.Test(httptest.NewRequest("POST", "/api/expand", nil), -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+
+	// Тест: Запись без ключа
+	t.Run("missing key", func(t *testing.T) {
+		mockKeyService.EXPECT().
+			Authenticate(gomock.Any(), "").
+			Return(nil, service.ErrUnauthorized)
+
+		resp, err := server.app.Test(httptest.NewRequest("POST", "/", nil), -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	})
+
+	// Тест: Запись с валидным ключом
+	t.Run("valid key", func(t *testing.T) {
+		mockKeyService.EXPECT().
+			Authenticate(gomock.Any(), "sk_valid").
+			Return(&model.APIKey{ID: 1, Name: "marketing"}, nil)
+
+		reqst := httptest.NewRequest("POST", "/", nil)
+		reqst.Header.Set("Authorization", "Bearer sk_valid")
+
+		resp, err := server.app.Test(reqst, -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+
+	// Тест: Статистика ссылки доступна только с ключом
+	t.Run("stats require key", func(t *testing.T) {
+		mockKeyService.EXPECT().
+			Authenticate(gomock.Any(), "").
+			Return(nil, service.ErrUnauthorized)
+
+		resp, err := server.app.Test(httptest.NewRequest("GET", "/abc123/stats", nil), -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+
+		mockKeyService.EXPECT().
+			Authenticate(gomock.Any(), "sk_valid").
+			Return(&model.APIKey{ID: 1, Name: "marketing"}, nil)
+
+		reqst := httptest.NewRequest("GET", "/abc123/stats", nil)
+		reqst.Header.Set("X-API-Key", "sk_valid")
+
+		resp, err = server.app.Test(reqst, -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+
+	// Тест: Админский API требует токен администратора, а не ключ
+	t.Run("admin token", func(t *testing.T) {
+		resp, err := server.app.Test(httptest.NewRequest("DELETE", "/api/admin/keys/1", nil), -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+
+		reqst := httptest.NewRequest("DELETE", "/api/admin/keys/1", nil)
+		reqst.Header.Set("X-Admin-Token", "admin-secret")
+
+		resp, err = server.app.Test(reqst, -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	})
+}
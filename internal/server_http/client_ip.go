@@ -0,0 +1,62 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"net/netip"
+	"strings"
+)
+
+// ClientIPLocal is the fiber.Ctx local holding the client address resolved
+// from X-Forwarded-For, see ClientIP.
+const ClientIPLocal = "client_ip"
+
+// resolveClientIP stores the address of the client behind the trusted
+// proxies. X-Forwarded-For is walked from the right, the hop each trusted
+// proxy appended, and the first untrusted address wins: entries further left
+// are whatever the client sent and can not be relied on.
+func (s *Server) resolveClientIP(c fiber.Ctx) error {
+	c.Locals(ClientIPLocal, s.clientIP(c))
+	return c.Next()
+}
+
+func (s *Server) clientIP(c fiber.Ctx) string {
+	remote, ok := netip.AddrFromSlice(c.Context().RemoteIP())
+	if !ok {
+		return c.IP()
+	}
+	ip := remote.Unmap()
+	if !s.trustedProxy(ip) {
+		return ip.String()
+	}
+
+	hops := strings.Split(c.Get(fiber.HeaderXForwardedFor), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		ip = hop.Unmap()
+		if !s.trustedProxy(ip) {
+			break
+		}
+	}
+	return ip.String()
+}
+
+func (s *Server) trustedProxy(ip netip.Addr) bool {
+	for _, prefix := range s.trustedProxies {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the client address of the request, the proxy's own
+// address when it is not a trusted one.
+func ClientIP(c fiber.Ctx) string {
+	if ip, ok := c.Locals(ClientIPLocal).(string); ok {
+		return ip
+	}
+	return c.IP()
+}
@@ -0,0 +1,49 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"io"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	// app.Test подключается с адреса 0.0.0.0
+	proxies := []netip.Prefix{netip.MustParsePrefix("0.0.0.0/32"), netip.MustParsePrefix("10.0.0.0/8")}
+
+	tests := []struct {
+		name    string
+		proxies []netip.Prefix
+		header  string
+		want    string
+	}{
+		{name: "без прокси заголовок игнорируется", header: "203.0.113.7", want: "0.0.0.0"},
+		{name: "прокси без заголовка", proxies: proxies, want: "0.0.0.0"},
+		{name: "клиент за прокси", proxies: proxies, header: "203.0.113.7", want: "203.0.113.7"},
+		{name: "подделанный адрес слева", proxies: proxies, header: "6.6.6.6, 203.0.113.7", want: "203.0.113.7"},
+		{name: "цепочка доверенных прокси", proxies: proxies, header: "203.0.113.7, 10.0.0.2, 10.0.0.3", want: "203.0.113.7"},
+		{name: "IPv6", proxies: proxies, header: "2001:db8::1", want: "2001:db8::1"},
+		{name: "мусор в заголовке", proxies: proxies, header: "not-an-ip, 10.0.0.2", want: "10.0.0.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewServer(ServerConfig{TrustedProxies: tt.proxies, Logger: zap.NewNop()})
+			server.app.Get("/ip", func(c fiber.Ctx) error { return c.SendString(ClientIP(c)) })
+
+			reqst := httptest.NewRequest("GET", "/ip", nil)
+			if tt.header != "" {
+				reqst.Header.Set("X-Forwarded-For", tt.header)
+			}
+			resp, err := server.app.Test(reqst, -1)
+			require.NoError(t, err)
+
+			body, _ := io.ReadAll(resp.Body)
+			assert.Equal(t, tt.want, string(body))
+		})
+	}
+}
@@ -4,26 +4,51 @@ import (
 	"fmt"
 	"github.com/gofiber/fiber/v3"
 	"go.uber.org/zap"
+	"net/netip"
 )
 
 type ServerConfig struct {
-	Controllers []Controller
-	Logger      *zap.Logger
+	Controllers      []Controller
+	AdminControllers []Controller
+	Auth             Authenticator
+	AdminToken       string
+	RateLimiter      RateLimiter
+	RateLimits       RateLimits
+	TrustedProxies   []netip.Prefix
+	Observer         RequestObserver
+	Logger           *zap.Logger
 }
 
 type Server struct {
-	Controller []Controller
-	app        *fiber.App
-	Logger     *zap.Logger
+	Controller      []Controller
+	AdminController []Controller
+	app             *fiber.App
+	auth            Authenticator
+	adminToken      string
+	limiter         RateLimiter
+	rateLimits      RateLimits
+	trustedProxies  []netip.Prefix
+	observer        RequestObserver
+	Logger          *zap.Logger
 }
 
+// NewServer builds the HTTP server. A nil Auth leaves mutating routes open,
+// a nil RateLimiter leaves every route unlimited, a nil Observer records nothing.
+// Client addresses come from X-Forwarded-For only behind TrustedProxies.
 func NewServer(config ServerConfig) *Server {
 	app := fiber.New()
 
 	s := &Server{
-		Controller: config.Controllers,
-		app:        app,
-		Logger:     config.Logger,
+		Controller:      config.Controllers,
+		AdminController: config.AdminControllers,
+		app:             app,
+		auth:            config.Auth,
+		adminToken:      config.AdminToken,
+		limiter:         config.RateLimiter,
+		rateLimits:      config.RateLimits,
+		trustedProxies:  config.TrustedProxies,
+		observer:        config.Observer,
+		Logger:          config.Logger,
 	}
 
 	s.registerRoutes()
@@ -44,6 +69,25 @@ func (s *Server) Shutdown() error {
 }
 
 func (s *Server) registerRoutes() {
+	if s.observer != nil {
+		s.app.Use(s.observe)
+	}
+	s.app.Use(s.resolveClientIP)
+	if s.auth != nil && s.limiter != nil {
+		s.app.Use(s.authRateLimit)
+	}
+	if s.auth != nil {
+		s.app.Use(s.requireAPIKey)
+	}
+	if s.limiter != nil {
+		s.app.Use(s.rateLimit)
+	}
+
+	for _, controller := range s.AdminController {
+		router := s.app.Group(adminPrefix+controller.Name(), s.requireAdminToken)
+		controller.Register(router)
+	}
+
 	for _, controller := range s.Controller {
 		router := s.app.Group(controller.Name())
 		controller.Register(router)
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v3"
+	"strings"
+	"time"
+)
+
+// RequestObserver records every request once it has been answered.
+type RequestObserver interface {
+	ObserveRequest(method, route string, status int, elapsed time.Duration)
+}
+
+// unrouted labels responses written by middleware before any route matched:
+// unknown paths, missing API keys and rate limited requests.
+const unrouted = "unrouted"
+
+// observe reports the matched route pattern rather than the path, so short
+// codes do not end up in metric labels. It runs before every other
+// middleware to see rejected requests too.
+func (s *Server) observe(c fiber.Ctx) error {
+	start := time.Now()
+	// fasthttp reuses the request buffer, the method must outlive the handler.
+	method := strings.Clone(c.Method())
+	// Handlers added with Use on the same path share one route, so the
+	// route only changes once a controller route has matched.
+	middleware := c.Route()
+	err := c.Next()
+
+	status := c.Response().StatusCode()
+	if err != nil {
+		// The error handler writes the response after the middleware chain returns.
+		status = fiber.StatusInternalServerError
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			status = fiberErr.Code
+		}
+	}
+
+	route := c.Route().Path
+	if c.Route() == middleware {
+		route = unrouted
+	}
+	s.observer.ObserveRequest(method, route, status, time.Since(start))
+	return err
+}
@@ -0,0 +1,69 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+	"urlShortener/internal/service"
+	mockService "urlShortener/mocks"
+)
+
+type observedRequest struct {
+	method string
+	route  string
+	status int
+}
+
+type recordingObserver struct {
+	mu       sync.Mutex
+	requests []observedRequest
+}
+
+func (o *recordingObserver) ObserveRequest(method, route string, status int, elapsed time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.requests = append(o.requests, observedRequest{method: method, route: route, status: status})
+}
+
+func TestObserveRequests(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockKeyService := mockService.NewMockKeyServiceInterface(ctrl)
+	mockKeyService.EXPECT().
+		Authenticate(gomock.Any(), "").
+		Return(nil, service.ErrUnauthorized)
+
+	observer := &recordingObserver{}
+	server := NewServer(ServerConfig{
+		Controllers: []Controller{stubController{}},
+		Auth:        mockKeyService,
+		Observer:    observer,
+		Logger:      zap.NewNop(),
+	})
+
+	for _, reqst := range []struct{ method, path string }{
+		{"GET", "/abc123"},
+		{"GET", "/xyz789"},
+		{"POST", "/"},
+		{"GET", "/no/such/route"},
+	} {
+		_, err := server.app.Test(httptest.NewRequest(reqst.method, reqst.path, nil), -1)
+		require.NoError(t, err)
+	}
+
+	// Коды ссылок не попадают в метки: оба запроса учтены по шаблону маршрута,
+	// отклонённые до маршрутизации запросы тоже учитываются
+	assert.Equal(t, []observedRequest{
+		{method: "GET", route: "/:id", status: fiber.StatusOK},
+		{method: "GET", route: "/:id", status: fiber.StatusOK},
+		{method: "POST", route: unrouted, status: fiber.StatusUnauthorized},
+		{method: "GET", route: unrouted, status: fiber.StatusNotFound},
+	}, observer.requests)
+}
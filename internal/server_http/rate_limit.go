@@ -0,0 +1,135 @@
+package http
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"urlShortener/internal/model"
+)
+
+const (
+	apiPrefix = "/api/"
+	batchPath = "/api/batch"
+
+	// RateChargeLocal is the fiber.Ctx local holding the func a per-item
+	// route charges its cost with, see ChargeRate.
+	RateChargeLocal = "rate_charge"
+
+	headerRateLimitLimit     = "X-RateLimit-Limit"
+	headerRateLimitRemaining = "X-RateLimit-Remaining"
+	headerRateLimitReset     = "X-RateLimit-Reset"
+)
+
+// RateLimiter holds the token buckets. Keys are namespaced by budget and
+// client, so one store serves both budgets.
+type RateLimiter interface {
+	Take(ctx context.Context, key string, limit model.RateLimit, cost int, now time.Time) (model.RateDecision, error)
+}
+
+// RateLimits are the per-client budgets, a zero limit turns its budget off.
+type RateLimits struct {
+	Create   model.RateLimit
+	Redirect model.RateLimit
+	Auth     model.RateLimit
+}
+
+// authRateLimit charges every request that needs an API key to the per-IP
+// auth budget before the key is looked up, so floods of missing or invalid
+// keys are throttled without a key lookup each.
+func (s *Server) authRateLimit(c fiber.Ctx) error {
+	if !s.rateLimits.Auth.Enabled() || !needsAPIKey(c) {
+		return c.Next()
+	}
+	if !s.take(c, "auth", s.rateLimits.Auth, "ip:"+ClientIP(c), 1) {
+		return RateLimitExceeded(c)
+	}
+	return c.Next()
+}
+
+// rateLimit charges mutating requests to the create budget and public reads
+// outside the API to the redirect budget. It runs after requireAPIKey, so an
+// authenticated client is limited by key rather than by IP. A limiter
+// failure lets the request through.
+//
+// A batch costs one token per item, which is only known once the body is
+// read, so the handler charges it through ChargeRate instead.
+func (s *Server) rateLimit(c fiber.Ctx) error {
+	budget, limit := s.budgetFor(c)
+	if !limit.Enabled() {
+		return c.Next()
+	}
+
+	if c.Method() == fiber.MethodPost && c.Path() == batchPath {
+		c.Locals(RateChargeLocal, func(cost int) bool {
+			return s.take(c, budget, limit, clientKey(c), cost)
+		})
+		return c.Next()
+	}
+
+	if !s.take(c, budget, limit, clientKey(c), 1) {
+		return RateLimitExceeded(c)
+	}
+	return c.Next()
+}
+
+// take charges cost tokens to client's bucket and sets the rate limit headers.
+func (s *Server) take(c fiber.Ctx, budget string, limit model.RateLimit, client string, cost int) bool {
+	decision, err := s.limiter.Take(c.Context(), budget+":"+client, limit, cost, time.Now())
+	if err != nil {
+		s.Logger.Error("error taking rate limit token", zap.String("budget", budget), zap.Error(err))
+		return true
+	}
+
+	c.Set(headerRateLimitLimit, strconv.Itoa(decision.Limit))
+	c.Set(headerRateLimitRemaining, strconv.Itoa(decision.Remaining))
+	c.Set(headerRateLimitReset, ceilSeconds(decision.Reset))
+	if !decision.Allowed {
+		c.Set(fiber.HeaderRetryAfter, ceilSeconds(decision.RetryAfter))
+	}
+	return decision.Allowed
+}
+
+// ChargeRate charges cost tokens to the budget of a request whose cost
+// rateLimit left to the handler. It reports whether the request may go on,
+// requests without a budget always may.
+func ChargeRate(c fiber.Ctx, cost int) bool {
+	if charge, ok := c.Locals(RateChargeLocal).(func(int) bool); ok {
+		return charge(cost)
+	}
+	return true
+}
+
+// RateLimitExceeded answers a request rejected by the rate limiter.
+func RateLimitExceeded(c fiber.Ctx) error {
+	return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": "rate limit exceeded"})
+}
+
+func (s *Server) budgetFor(c fiber.Ctx) (string, model.RateLimit) {
+	if strings.HasPrefix(c.Path(), adminPrefix) {
+		return "", model.RateLimit{}
+	}
+	switch c.Method() {
+	case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete:
+		return "create", s.rateLimits.Create
+	case fiber.MethodGet, fiber.MethodHead:
+		if !strings.HasPrefix(c.Path(), apiPrefix) {
+			return "redirect", s.rateLimits.Redirect
+		}
+	}
+	return "", model.RateLimit{}
+}
+
+func clientKey(c fiber.Ctx) string {
+	if key, ok := c.Locals(APIKeyLocal).(*model.APIKey); ok && key != nil {
+		return "key:" + strconv.Itoa(key.ID)
+	}
+	return "ip:" + ClientIP(c)
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
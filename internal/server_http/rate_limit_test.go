@@ -0,0 +1,147 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"urlShortener/internal/model"
+	"urlShortener/internal/repository"
+	"urlShortener/internal/service"
+	mockService "urlShortener/mocks"
+)
+
+func TestRateLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockKeyService := mockService.NewMockKeyServiceInterface(ctrl)
+	mockKeyService.EXPECT().
+		Authenticate(gomock.Any(), "sk_valid").
+		Return(&model.APIKey{ID: 1, Name: "marketing"}, nil).
+		AnyTimes()
+
+	server := NewServer(ServerConfig{
+		Controllers: []Controller{stubController{}},
+		Auth:        mockKeyService,
+		RateLimiter: repository.NewRateLimitStorage(),
+		RateLimits: RateLimits{
+			Create:   model.RateLimit{Rate: 0.01, Burst: 1},
+			Redirect: model.RateLimit{Rate: 0.01, Burst: 2},
+		},
+		Logger: zap.NewNop(),
+	})
+
+	create := func(key string) *http.Response {
+		reqst := httptest.NewRequest("POST", "/", nil)
+		reqst.Header.Set("X-API-Key", key)
+		resp, err := server.app.Test(reqst, -1)
+		require.NoError(t, err)
+		return resp
+	}
+
+	// Тест: Создание ограничено отдельным бюджетом
+	t.Run("create budget", func(t *testing.T) {
+		resp := create("sk_valid")
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, "1", resp.Header.Get("X-RateLimit-Limit"))
+		assert.Equal(t, "0", resp.Header.Get("X-RateLimit-Remaining"))
+
+		resp = create("sk_valid")
+		assert.Equal(t, fiber.StatusTooManyRequests, resp.StatusCode)
+		assert.Equal(t, "100", resp.Header.Get("Retry-After"))
+	})
+
+	// Тест: Редиректы расходуют свой бюджет
+	t.Run("redirect budget", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			resp, err := server.app.Test(httptest.NewRequest("GET", "/abc123", nil), -1)
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		}
+
+		resp, err := server.app.Test(httptest.NewRequest("GET", "/abc123", nil), -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusTooManyRequests, resp.StatusCode)
+		assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+	})
+}
+
+func TestRateLimitBatch(t *testing.T) {
+	server := NewServer(ServerConfig{
+		Controllers: []Controller{stubController{}},
+		RateLimiter: repository.NewRateLimitStorage(),
+		RateLimits:  RateLimits{Create: model.RateLimit{Rate: 1, Burst: 5}},
+		Logger:      zap.NewNop(),
+	})
+
+	post := func(target string) *http.Response {
+		resp, err := server.app.Test(httptest.NewRequest("POST", target, nil), -1)
+		require.NoError(t, err)
+		return resp
+	}
+
+	// Тест: Пакет больше запаса проходит при полной корзине и оставляет долг
+	resp := post("/api/batch?items=8")
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "0", resp.Header.Get("X-RateLimit-Remaining"))
+
+	// Тест: Пока долг не погашен, создание отклоняется
+	resp = post("/")
+	assert.Equal(t, fiber.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "4", resp.Header.Get("Retry-After"))
+
+	resp = post("/api/batch?items=2")
+	assert.Equal(t, fiber.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "5", resp.Header.Get("Retry-After"))
+}
+
+func TestAuthRateLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockKeyService := mockService.NewMockKeyServiceInterface(ctrl)
+
+	server := NewServer(ServerConfig{
+		Controllers:    []Controller{stubController{}},
+		Auth:           mockKeyService,
+		RateLimiter:    repository.NewRateLimitStorage(),
+		RateLimits:     RateLimits{Auth: model.RateLimit{Rate: 0.01, Burst: 2}},
+		TrustedProxies: []netip.Prefix{netip.MustParsePrefix("0.0.0.0/32")},
+		Logger:         zap.NewNop(),
+	})
+
+	post := func(clientIP string) *http.Response {
+		reqst := httptest.NewRequest("POST", "/", nil)
+		reqst.Header.Set("X-API-Key", "sk_invalid")
+		reqst.Header.Set("X-Forwarded-For", clientIP)
+		resp, err := server.app.Test(reqst, -1)
+		require.NoError(t, err)
+		return resp
+	}
+
+	// Тест: Неверные ключи проверяются, пока не исчерпан бюджет IP
+	mockKeyService.EXPECT().
+		Authenticate(gomock.Any(), "sk_invalid").
+		Return(nil, service.ErrUnauthorized).
+		Times(3)
+
+	assert.Equal(t, fiber.StatusUnauthorized, post("203.0.113.7").StatusCode)
+	assert.Equal(t, fiber.StatusUnauthorized, post("203.0.113.7").StatusCode)
+
+	// Тест: Дальше запросы отклоняются без обращения к хранилищу ключей
+	assert.Equal(t, fiber.StatusTooManyRequests, post("203.0.113.7").StatusCode)
+
+	// Тест: Клиенты за прокси расходуют собственные бюджеты
+	assert.Equal(t, fiber.StatusUnauthorized, post("198.51.100.4").StatusCode)
+
+	// Тест: Публичные запросы бюджет авторизации не расходуют
+	resp, err := server.app.Test(httptest.NewRequest("GET", "/abc123", nil), -1)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
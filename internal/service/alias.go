@@ -0,0 +1,45 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	aliasMinLength = 3
+	aliasMaxLength = 64
+)
+
+// reservedAliases are path segments the HTTP server uses or may use for its own routes.
+var reservedAliases = map[string]struct{}{
+	"admin":   {},
+	"api":     {},
+	"expand":  {},
+	"health":  {},
+	"login":   {},
+	"logout":  {},
+	"metrics": {},
+	"stats":   {},
+	"static":  {},
+}
+
+func ValidateAlias(alias string) error {
+	if len(alias) < aliasMinLength || len(alias) > aliasMaxLength {
+		return fmt.Errorf("%w: length must be between %d and %d characters", ErrInvalidAlias, aliasMinLength, aliasMaxLength)
+	}
+
+	for _, r := range alias {
+		if !isAliasRune(r) {
+			return fmt.Errorf("%w: only latin letters, digits, '-' and '_' are allowed", ErrInvalidAlias)
+		}
+	}
+
+	if _, reserved := reservedAliases[strings.ToLower(alias)]; reserved {
+		return fmt.Errorf("%w: %q is reserved", ErrInvalidAlias, alias)
+	}
+	return nil
+}
+
+func isAliasRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_'
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestValidateAlias(t *testing.T) {
+	tests := []struct {
+		name    string
+		alias   string
+		wantErr bool
+	}{
+		{name: "letters and digits", alias: "promo2026"},
+		{name: "dash and underscore", alias: "summer-sale_v2"},
+		{name: "min length", alias: "abc"},
+		{name: "max length", alias: strings.Repeat("a", aliasMaxLength)},
+		{name: "too short", alias: "ab", wantErr: true},
+		{name: "empty", alias: "", wantErr: true},
+		{name: "too long", alias: strings.Repeat("a", aliasMaxLength+1), wantErr: true},
+		{name: "slash", alias: "a/b/c", wantErr: true},
+		{name: "space", alias: "my link", wantErr: true},
+		{name: "dot", alias: "file.txt", wantErr: true},
+		{name: "query", alias: "abc?x=1", wantErr: true},
+		{name: "cyrillic", alias: "ссылка", wantErr: true},
+		{name: "reserved api", alias: "api", wantErr: true},
+		{name: "reserved admin", alias: "admin", wantErr: true},
+		{name: "reserved health", alias: "health", wantErr: true},
+		{name: "reserved metrics", alias: "metrics", wantErr: true},
+		{name: "reserved stats", alias: "stats", wantErr: true},
+		{name: "reserved any case", alias: "API", wantErr: true},
+		{name: "reserved word as part", alias: "api-docs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAlias(tt.alias)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidAlias)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
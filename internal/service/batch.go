@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"slices"
+	"time"
+	"urlShortener/internal/model"
+)
+
+// BatchResult is the outcome of one request of a batch, either a response or an error.
+type BatchResult struct {
+	Response *model.Response
+	Err      error
+}
+
+type batchLink struct {
+	index int
+	link  *model.Link
+}
+
+// CreateShortURLs shortens reqs with one bulk insert. Every request goes
+// through the same checks as CreateShortURL and fails on its own; results
+// keep the order of reqs. Requests for the same permanent URL within the
+// batch share one link. The error is only set when the batch as a whole failed.
+func (s *ShortenerService) CreateShortURLs(ctx context.Context, reqs []model.Request) ([]BatchResult, error) {
+	if s.config.BatchMaxItems > 0 && len(reqs) > s.config.BatchMaxItems {
+		return nil, fmt.Errorf("%w: at most %d items are allowed", ErrBatchTooLarge, s.config.BatchMaxItems)
+	}
+
+	results := make([]BatchResult, len(reqs))
+	sameAs := make(map[int]int)
+	firstByURL := make(map[string]int)
+	aliases := make(map[string]struct{})
+	var pending []batchLink
+	createdAt := creationTime()
+
+	prepared, expiries, errs := s.prepareAll(ctx, reqs)
+	for i, req := range prepared {
+		expiresAt := expiries[i]
+		if errs[i] != nil {
+			results[i].Err = errs[i]
+			continue
+		}
+
+		if s.shared(req, expiresAt) {
+			key := req.Owner + "\x00" + req.URL
+			if first, ok := firstByURL[key]; ok {
+				sameAs[i] = first
+				continue
+			}
+			existURL, err := s.existing(ctx, req)
+			if err != nil {
+				results[i].Err = err
+				continue
+			}
+			if existURL != "" {
+				results[i].Response = &model.Response{URL: s.shortLink(existURL)}
+				continue
+			}
+			firstByURL[key] = i
+		}
+
+		if req.Alias != "" {
+			if _, taken := aliases[req.Alias]; taken {
+				results[i].Err = ErrAliasTaken
+				continue
+			}
+			aliases[req.Alias] = struct{}{}
+		}
+
+		pending = append(pending, batchLink{index: i, link: &model.Link{
+			ShortURL:     req.Alias,
+			OriginalURL:  req.URL,
+			RedirectType: req.RedirectType,
+			ExpiresAt:    expiresAt,
+			Owner:        req.Owner,
+			CreatedAt:    createdAt,
+		}})
+	}
+
+	if err := s.insertBatch(ctx, pending, aliases, results); err != nil {
+		s.logger.Error("error creating short urls in bulk", zap.Int("count", len(pending)), zap.Error(err))
+		return nil, err
+	}
+
+	for i, first := range sameAs {
+		results[i] = results[first]
+	}
+	return results, nil
+}
+
+// prepareAll runs prepare over a copy of reqs, up to BATCH_CONCURRENCY
+// requests at a time: screening may cost an HTTP call per URL, and one after
+// another a full batch would hold the request for that many timeouts.
+func (s *ShortenerService) prepareAll(ctx context.Context, reqs []model.Request) ([]model.Request, []*time.Time, []error) {
+	prepared := slices.Clone(reqs)
+	expiries := make([]*time.Time, len(reqs))
+	errs := make([]error, len(reqs))
+
+	var group errgroup.Group
+	group.SetLimit(max(s.config.BatchConcurrency, 1))
+	for i := range prepared {
+		group.Go(func() error {
+			expiries[i], errs[i] = s.prepare(ctx, &prepared[i])
+			return nil
+		})
+	}
+	group.Wait()
+	return prepared, expiries, errs
+}
+
+// insertBatch assigns IDs and codes to pending links and stores them. A
+// generated code that turns out to be taken by an alias is replaced by the
+// code of a fresh ID, a taken alias fails its own request.
+func (s *ShortenerService) insertBatch(ctx context.Context, pending []batchLink, aliases map[string]struct{}, results []BatchResult) error {
+	byID := make(map[int]batchLink, len(pending))
+	for len(pending) > 0 {
+		if err := s.assignIDs(ctx, pending, aliases); err != nil {
+			return err
+		}
+
+		links := make([]*model.Link, len(pending))
+		for i, item := range pending {
+			links[i] = item.link
+			byID[item.link.ID] = item
+		}
+
+		taken, err := s.repository.CreateShortURLs(ctx, links)
+		if err != nil {
+			return err
+		}
+
+		takenIDs := make(map[int]struct{}, len(taken))
+		var retry []batchLink
+		for _, id := range taken {
+			takenIDs[id] = struct{}{}
+			item := byID[id]
+			if _, alias := aliases[item.link.ShortURL]; alias {
+				results[item.index].Err = ErrAliasTaken
+				continue
+			}
+			item.link.ShortURL = ""
+			retry = append(retry, item)
+		}
+
+		for _, item := range pending {
+			if _, skipped := takenIDs[item.link.ID]; skipped {
+				continue
+			}
+			results[item.index].Response = &model.Response{
+				URL:       s.shortLink(item.link.ShortURL),
+				ExpiresAt: item.link.ExpiresAt,
+			}
+		}
+		pending = retry
+	}
+	return nil
+}
+
+// assignIDs gives every pending link a fresh ID and a generated code unless
+// it carries an alias. Codes that collide with an alias of the batch are skipped.
+func (s *ShortenerService) assignIDs(ctx context.Context, pending []batchLink, aliases map[string]struct{}) error {
+	ids, err := s.repository.GetNextIDs(ctx, len(pending))
+	if err != nil {
+		return err
+	}
+
+	for i, item := range pending {
+		item.link.ID = ids[i]
+		if item.link.ShortURL != "" {
+			continue
+		}
+		for {
+			item.link.ShortURL = s.generator.Encode(item.link.ID)
+			if _, alias := aliases[item.link.ShortURL]; !alias {
+				break
+			}
+			if item.link.ID, err = s.repository.GetNextID(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
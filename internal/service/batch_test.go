@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+	"urlShortener/internal/model"
+)
+
+func TestCreateShortURLs(t *testing.T) {
+	svc := newTestService()
+	ctx := context.Background()
+
+	existing, err := svc.CreateShortURL(ctx, model.Request{URL: "https://example.com/existing"})
+	require.NoError(t, err)
+	_, err = svc.CreateShortURL(ctx, model.Request{URL: "https://example.com/alias", Alias: "taken"})
+	require.NoError(t, err)
+
+	results, err := svc.CreateShortURLs(ctx, []model.Request{
+		{URL: "https://example.com/a"},
+		{URL: "javascript:alert(1)"},
+		{URL: "HTTPS://EXAMPLE.com/a"},
+		{URL: "https://example.com/existing"},
+		{URL: "https://example.com/b", Alias: "taken"},
+		{URL: "https://example.com/c", Alias: "fresh"},
+		{URL: "https://example.com/d", Alias: "fresh"},
+		{URL: "https://example.com/e", TTL: "1h"},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 8)
+
+	// Результаты идут в порядке запросов
+	require.NoError(t, results[0].Err)
+	assert.ErrorIs(t, results[1].Err, ErrInvalidURL)
+	assert.Equal(t, results[0].Response.URL, results[2].Response.URL)
+	assert.Equal(t, existing.URL, results[3].Response.URL)
+	assert.ErrorIs(t, results[4].Err, ErrAliasTaken)
+	assert.Equal(t, svc.shortLink("fresh"), results[5].Response.URL)
+	assert.ErrorIs(t, results[6].Err, ErrAliasTaken)
+	require.NoError(t, results[7].Err)
+	assert.NotNil(t, results[7].Response.ExpiresAt)
+
+	link, err := svc.GetOriginalURL(ctx, results[0].Response.URL[len(svc.shortLink("")):])
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/a", link.OriginalURL)
+
+	svc.config.BatchMaxItems = 2
+	_, err = svc.CreateShortURLs(ctx, make([]model.Request, 3))
+	assert.ErrorIs(t, err, ErrBatchTooLarge)
+}
+
+// Сгенерированный код, совпавший с псевдонимом, заменяется новым
+func TestCreateShortURLsSkipsAliasCodes(t *testing.T) {
+	svc := newTestService()
+	ctx := context.Background()
+
+	_, err := svc.repository.GetNextID(ctx)
+	require.NoError(t, err)
+	require.NoError(t, svc.repository.CreateShortURL(ctx, &model.Link{ID: 1, ShortURL: svc.generator.Encode(2), OriginalURL: "https://example.com/"}))
+
+	reqs := make([]model.Request, 3)
+	for i := range reqs {
+		reqs[i] = model.Request{URL: fmt.Sprintf("https://example.com/%d", i)}
+	}
+
+	results, err := svc.CreateShortURLs(ctx, reqs)
+	require.NoError(t, err)
+
+	seen := map[string]bool{}
+	for _, result := range results {
+		require.NoError(t, result.Err)
+		assert.False(t, seen[result.Response.URL])
+		seen[result.Response.URL] = true
+	}
+	assert.False(t, seen[svc.shortLink(svc.generator.Encode(2))])
+}
+
+// slowScreener отвечает с задержкой, как внешний сервис репутации, и запоминает пик параллельных проверок
+type slowScreener struct {
+	mu      sync.Mutex
+	running int
+	peak    int
+}
+
+func (s *slowScreener) Screen(ctx context.Context, url string) error {
+	s.mu.Lock()
+	s.running++
+	s.peak = max(s.peak, s.running)
+	s.mu.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	s.mu.Lock()
+	s.running--
+	s.mu.Unlock()
+	if url == "https://blocked.example/" {
+		return &BlockedError{Reason: ReasonReputation, Message: "bad reputation"}
+	}
+	return nil
+}
+
+func TestCreateShortURLsScreensConcurrently(t *testing.T) {
+	screener := &slowScreener{}
+	svc := newTestService()
+	svc.screener = screener
+	svc.config.BatchConcurrency = 4
+
+	reqs := make([]model.Request, 16)
+	for i := range reqs {
+		reqs[i] = model.Request{URL: fmt.Sprintf("https://example.com/%d", i)}
+	}
+	reqs[0].URL = "HTTPS://EXAMPLE.com/0"
+	reqs[5].URL = "https://blocked.example/"
+
+	start := time.Now()
+	results, err := svc.CreateShortURLs(context.Background(), reqs)
+	require.NoError(t, err)
+
+	// Проверки идут не больше чем по BATCH_CONCURRENCY сразу, а не по одной
+	assert.Equal(t, 4, screener.peak)
+	assert.Less(t, time.Since(start), 16*20*time.Millisecond)
+
+	// Порядок результатов и исходные запросы не меняются
+	assert.ErrorIs(t, results[5].Err, ErrURLBlocked)
+	for i, result := range results {
+		if i != 5 {
+			assert.NoError(t, result.Err)
+		}
+	}
+	assert.Equal(t, "HTTPS://EXAMPLE.com/0", reqs[0].URL)
+}
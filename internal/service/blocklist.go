@@ -0,0 +1,159 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"golang.org/x/net/idna"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type blocklist struct {
+	domains  map[string]struct{}
+	patterns []*regexp.Regexp
+}
+
+// BlocklistScreener rejects URLs whose host is a listed domain or one of
+// its subdomains, or that match a listed regular expression.
+//
+// The file holds one entry per line: a domain such as "evil.example", or a
+// regular expression between slashes such as "/\.zip$/" matched against the
+// whole normalized URL. Empty lines and lines starting with '#' are skipped.
+type BlocklistScreener struct {
+	path    string
+	list    atomic.Pointer[blocklist]
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	logger  *zap.Logger
+}
+
+func NewBlocklistScreener(path string, logger *zap.Logger) (*BlocklistScreener, error) {
+	s := &BlocklistScreener{path: path, logger: logger}
+	if _, err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *BlocklistScreener) Screen(ctx context.Context, rawURL string) error {
+	list := s.list.Load()
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	host := u.Hostname()
+	for {
+		if _, blocked := list.domains[host]; blocked {
+			return &BlockedError{Reason: ReasonBlockedDomain, Message: fmt.Sprintf("domain %q is blocked", host)}
+		}
+		dot := strings.IndexByte(host, '.')
+		if dot < 0 {
+			break
+		}
+		host = host[dot+1:]
+	}
+
+	for _, pattern := range list.patterns {
+		if pattern.MatchString(rawURL) {
+			return &BlockedError{Reason: ReasonBlockedPattern, Message: "URL matches a blocked pattern"}
+		}
+	}
+	return nil
+}
+
+// Reload reads the file again if it changed since the last load. A file
+// that fails to parse leaves the previous list in place.
+func (s *BlocklistScreener) Reload() (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return false, err
+	}
+	if s.list.Load() != nil && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return false, nil
+	}
+
+	list, err := readBlocklist(s.path)
+	if err != nil {
+		return false, err
+	}
+	s.list.Store(list)
+	s.modTime = info.ModTime()
+	s.size = info.Size()
+	return true, nil
+}
+
+// Watch polls the file every interval until ctx is done.
+func (s *BlocklistScreener) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := s.Reload()
+			if err != nil {
+				s.logger.Error("error reloading blocklist", zap.String("path", s.path), zap.Error(err))
+				continue
+			}
+			if reloaded {
+				list := s.list.Load()
+				s.logger.Info("blocklist reloaded",
+					zap.Int("domains", len(list.domains)), zap.Int("patterns", len(list.patterns)))
+			}
+		}
+	}
+}
+
+func readBlocklist(path string) (*blocklist, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	list := &blocklist{domains: make(map[string]struct{})}
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+
+		if len(entry) > 1 && strings.HasPrefix(entry, "/") && strings.HasSuffix(entry, "/") {
+			pattern, err := regexp.Compile(entry[1 : len(entry)-1])
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+			}
+			list.patterns = append(list.patterns, pattern)
+			continue
+		}
+
+		domain, err := idna.Lookup.ToASCII(strings.TrimSuffix(strings.ToLower(entry), "."))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid domain %q: %w", path, line, entry, err)
+		}
+		list.domains[domain] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"time"
+	"urlShortener/internal/model"
+	"urlShortener/internal/utils"
+)
+
+const clickShutdownTimeout = 5 * time.Second
+
+// RecordClick queues a click for RunClickRecorder without blocking the
+// redirect. Clicks are dropped when the buffer is full.
+func (s *ShortenerService) RecordClick(click model.Click) {
+	click.IP = utils.AnonymizeIP(click.IP)
+
+	select {
+	case s.events <- click:
+	default:
+		s.logger.Warn("click buffer is full, dropping click", zap.String("short_url", click.ShortURL))
+	}
+}
+
+// GetStats returns the click statistics of a link its owner may read.
+func (s *ShortenerService) GetStats(ctx context.Context, url string, owner string) (*model.Stats, error) {
+	link, err := s.repository.GetOriginalURL(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	if !ownedBy(link, owner) {
+		return nil, ErrForbidden
+	}
+
+	stats, err := s.clicks.GetStats(ctx, url)
+	if err != nil {
+		s.logger.Error("error getting click stats", zap.String("short_url", url), zap.Error(err))
+		return nil, err
+	}
+	return stats, nil
+}
+
+// RunClickRecorder persists queued clicks in batches until ctx is done,
+// then flushes whatever is left in the buffer.
+func (s *ShortenerService) RunClickRecorder(ctx context.Context) {
+	batchSize := max(s.config.ClickBatchSize, 1)
+	flushEvery := s.config.ClickFlush
+	if flushEvery <= 0 {
+		flushEvery = time.Second
+	}
+	ticker := time.NewTicker(flushEvery)
+	defer ticker.Stop()
+
+	batch := make([]model.Click, 0, batchSize)
+	flush := func(ctx context.Context) {
+		if len(batch) == 0 {
+			return
+		}
+		if err := s.clicks.SaveClicks(ctx, batch); err != nil {
+			s.logger.Error("error saving clicks", zap.Int("count", len(batch)), zap.Error(err))
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case click := <-s.events:
+			batch = append(batch, click)
+			if len(batch) >= batchSize {
+				flush(ctx)
+			}
+		case <-ticker.C:
+			flush(ctx)
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), clickShutdownTimeout)
+			defer cancel()
+			for {
+				select {
+				case click := <-s.events:
+					batch = append(batch, click)
+					if len(batch) >= batchSize {
+						flush(shutdownCtx)
+					}
+				default:
+					flush(shutdownCtx)
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+	"urlShortener/internal/initialize"
+	"urlShortener/internal/model"
+	"urlShortener/internal/repository"
+)
+
+func newClickService(config *initialize.Config, clicks repository.ClickRepository) *ShortenerService {
+	logger := zap.NewNop()
+	return NewShortenerService(Deps{
+		Repository:      repository.NewURLStorage(logger),
+		ClickRepository: clicks,
+		Config:          config,
+		Logger:          logger,
+	})
+}
+
+func TestRecordClickDropsWhenFull(t *testing.T) {
+	svc := newClickService(&initialize.Config{ClickBuffer: 2}, repository.NewClickStorage(16, zap.NewNop()))
+
+	// Клики сверх буфера отбрасываются, редирект не ждёт
+	svc.RecordClick(model.Click{ShortURL: "b", IP: "203.0.113.77"})
+	svc.RecordClick(model.Click{ShortURL: "c", IP: "2001:db8:abcd:12::1"})
+	svc.RecordClick(model.Click{ShortURL: "d", IP: "203.0.113.78"})
+
+	require.Len(t, svc.events, 2)
+	first, second := <-svc.events, <-svc.events
+	assert.Equal(t, "b", first.ShortURL)
+	assert.Equal(t, "203.0.113.0", first.IP)
+	assert.Equal(t, "c", second.ShortURL)
+	assert.Equal(t, "2001:db8:abcd::", second.IP)
+}
+
+func TestRunClickRecorder(t *testing.T) {
+	clicks := repository.NewClickStorage(16, zap.NewNop())
+	svc := newClickService(&initialize.Config{ClickBuffer: 16, ClickBatchSize: 2, ClickFlush: time.Hour}, clicks)
+	day := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		svc.RunClickRecorder(ctx)
+		close(done)
+	}()
+
+	// Полный пакет записывается, не дожидаясь таймера
+	svc.RecordClick(model.Click{ShortURL: "b", ClickedAt: day})
+	svc.RecordClick(model.Click{ShortURL: "b", ClickedAt: day})
+	assert.Eventually(t, func() bool {
+		stats, _ := clicks.GetStats(context.Background(), "b")
+		return stats.Total == 2
+	}, time.Second, time.Millisecond)
+
+	// При остановке буфер дочитывается и неполный пакет сохраняется
+	cancel()
+	<-done
+	for i := 0; i < 5; i++ {
+		svc.RecordClick(model.Click{ShortURL: "c", ClickedAt: day})
+	}
+	svc.RunClickRecorder(ctx)
+
+	stats, err := clicks.GetStats(context.Background(), "c")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), stats.Total)
+	assert.Empty(t, svc.events)
+}
+
+func TestGetStatsOwner(t *testing.T) {
+	clicks := repository.NewClickStorage(16, zap.NewNop())
+	svc := newClickService(&initialize.Config{}, clicks)
+	ctx := context.Background()
+	require.NoError(t, svc.repository.CreateShortURL(ctx, &model.Link{ID: 1, ShortURL: "b", OriginalURL: "https://example.com", Owner: "marketing"}))
+	require.NoError(t, svc.repository.CreateShortURL(ctx, &model.Link{ID: 2, ShortURL: "c", OriginalURL: "https://example.org"}))
+
+	tests := []struct {
+		name    string
+		url     string
+		owner   string
+		wantErr error
+	}{
+		{name: "владелец", url: "b", owner: "marketing"},
+		{name: "авторизация выключена", url: "b"},
+		{name: "чужой ключ", url: "b", owner: "sales", wantErr: ErrForbidden},
+		{name: "ссылка без владельца", url: "c", owner: "sales"},
+		{name: "не найдена", url: "d", owner: "marketing", wantErr: repository.ErrLinkNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats, err := svc.GetStats(ctx, tt.url, tt.owner)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, stats)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.url, stats.ShortURL)
+		})
+	}
+}
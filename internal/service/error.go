@@ -0,0 +1,21 @@
+package service
+
+import "errors"
+
+var (
+	ErrInvalidRedirectType = errors.New("redirect type must be one of 301, 302, 307, 308")
+	ErrInvalidAlias        = errors.New("invalid alias")
+	ErrAliasTaken          = errors.New("alias is already taken")
+	ErrInvalidExpiry       = errors.New("invalid expiry")
+	ErrLinkExpired         = errors.New("link expired")
+	ErrLinkDeleted         = errors.New("link deleted")
+	ErrEmptyURL            = errors.New("URL must not be nil")
+	ErrInvalidURL          = errors.New("invalid URL")
+	ErrURLBlocked          = errors.New("URL is blocked")
+	ErrEmptyKeyName        = errors.New("key name must not be empty")
+	ErrKeyNameTaken        = errors.New("key name is already taken")
+	ErrUnauthorized        = errors.New("invalid or missing API key")
+	ErrForbidden           = errors.New("link belongs to another owner")
+	ErrInvalidListQuery    = errors.New("invalid list query")
+	ErrBatchTooLarge       = errors.New("batch is too large")
+)
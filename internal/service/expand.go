@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"time"
+	"urlShortener/internal/model"
+)
+
+// ExpandURLs resolves many codes with a single repository lookup. Every
+// requested code gets an entry with the same outcome GET /:code would have.
+func (s *ShortenerService) ExpandURLs(ctx context.Context, codes []string) (map[string]model.ExpandResult, error) {
+	if s.config.BatchMaxItems > 0 && len(codes) > s.config.BatchMaxItems {
+		return nil, fmt.Errorf("%w: at most %d codes are allowed", ErrBatchTooLarge, s.config.BatchMaxItems)
+	}
+
+	unique := make([]string, 0, len(codes))
+	results := make(map[string]model.ExpandResult, len(codes))
+	for _, code := range codes {
+		if _, seen := results[code]; !seen {
+			results[code] = model.ExpandResult{Status: model.ExpandNotFound}
+			unique = append(unique, code)
+		}
+	}
+	if len(unique) == 0 {
+		return results, nil
+	}
+
+	links, err := s.repository.GetOriginalURLs(ctx, unique)
+	if err != nil {
+		s.logger.Error("error getting original urls", zap.Int("count", len(unique)), zap.Error(err))
+		return nil, err
+	}
+
+	now := time.Now()
+	for code, link := range links {
+		switch {
+		case link.DeletedAt != nil:
+			results[code] = model.ExpandResult{Status: model.ExpandDeleted}
+		case link.Expired(now):
+			results[code] = model.ExpandResult{Status: model.ExpandExpired}
+		default:
+			if err := screen(ctx, s.redirects, link.OriginalURL); err != nil {
+				results[code] = model.ExpandResult{Status: model.ExpandBlocked}
+				continue
+			}
+			results[code] = model.ExpandResult{Status: model.ExpandFound, URL: link.OriginalURL}
+		}
+	}
+	return results, nil
+}
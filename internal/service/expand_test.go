@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+	"urlShortener/internal/model"
+)
+
+func TestExpandURLs(t *testing.T) {
+	svc := newTestService()
+	ctx := context.Background()
+
+	_, err := svc.CreateShortURL(ctx, model.Request{URL: "https://example.com/live", Alias: "live"})
+	require.NoError(t, err)
+	_, err = svc.CreateShortURL(ctx, model.Request{URL: "https://example.com/gone", Alias: "gone"})
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteLink(ctx, "gone", ""))
+	past := time.Now().Add(-time.Hour)
+	require.NoError(t, svc.repository.CreateShortURL(ctx, &model.Link{ID: 100, ShortURL: "old", OriginalURL: "https://example.com/old", ExpiresAt: &past}))
+
+	results, err := svc.ExpandURLs(ctx, []string{"live", "gone", "old", "nope", "live"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]model.ExpandResult{
+		"live": {Status: model.ExpandFound, URL: "https://example.com/live"},
+		"gone": {Status: model.ExpandDeleted},
+		"old":  {Status: model.ExpandExpired},
+		"nope": {Status: model.ExpandNotFound},
+	}, results)
+
+	svc.config.BatchMaxItems = 1
+	_, err = svc.ExpandURLs(ctx, []string{"live", "gone"})
+	assert.ErrorIs(t, err, ErrBatchTooLarge)
+}
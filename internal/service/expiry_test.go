@@ -0,0 +1,171 @@
+package service_test
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"testing"
+	"time"
+	"urlShortener/internal/initialize"
+	"urlShortener/internal/model"
+	"urlShortener/internal/repository"
+	"urlShortener/internal/service"
+	"urlShortener/internal/utils"
+	mockService "urlShortener/mocks"
+)
+
+func newMockedService(repo service.SwapRepository, logger *zap.Logger) *service.ShortenerService {
+	generator, _ := utils.NewCodeGenerator("base62", "")
+	return service.NewShortenerService(service.Deps{
+		Repository: repo,
+		Generator:  generator,
+		Config:     &initialize.Config{HTTPHost: "localhost", HTTPPort: "3000", RedirectType: 302},
+		Logger:     logger,
+	})
+}
+
+func TestCreateShortURLExpiry(t *testing.T) {
+	future := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+	past := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name      string
+		ttl       string
+		expiresAt *time.Time
+		wantErr   error
+		expiresIn time.Duration // 0 — ссылка без срока
+		exact     *time.Time
+	}{
+		{name: "без срока"},
+		{name: "ttl", ttl: "1h", expiresIn: time.Hour},
+		{name: "expires_at в будущем", expiresAt: &future, exact: &future},
+		{name: "ttl и expires_at вместе", ttl: "1h", expiresAt: &future, wantErr: service.ErrInvalidExpiry},
+		{name: "expires_at в прошлом", expiresAt: &past, wantErr: service.ErrInvalidExpiry},
+		{name: "отрицательный ttl", ttl: "-1h", wantErr: service.ErrInvalidExpiry},
+		{name: "нулевой ttl", ttl: "0s", wantErr: service.ErrInvalidExpiry},
+		{name: "ttl не длительность", ttl: "tomorrow", wantErr: service.ErrInvalidExpiry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockRepo := mockService.NewMockSwapRepository(ctrl)
+			svc := newMockedService(mockRepo, zap.NewNop())
+			noDedupe := false
+			req := model.Request{URL: "https://example.com", TTL: tt.ttl, ExpiresAt: tt.expiresAt, Dedupe: &noDedupe}
+
+			// При ошибке срока хранилище не трогается
+			if tt.wantErr != nil {
+				_, err := svc.CreateShortURL(context.Background(), req)
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			var stored model.Link
+			mockRepo.EXPECT().GetNextID(gomock.Any()).Return(1, nil)
+			mockRepo.EXPECT().ShortURLExists(gomock.Any(), gomock.Any()).Return(false, nil)
+			mockRepo.EXPECT().CreateShortURL(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, link *model.Link) error {
+					stored = *link
+					return nil
+				})
+
+			before := time.Now()
+			resp, err := svc.CreateShortURL(context.Background(), req)
+			require.NoError(t, err)
+			assert.Equal(t, stored.ExpiresAt, resp.ExpiresAt)
+
+			switch {
+			case tt.exact != nil:
+				require.NotNil(t, stored.ExpiresAt)
+				assert.True(t, tt.exact.Equal(*stored.ExpiresAt))
+			case tt.expiresIn > 0:
+				require.NotNil(t, stored.ExpiresAt)
+				assert.WithinDuration(t, before.Add(tt.expiresIn), *stored.ExpiresAt, time.Second)
+			default:
+				assert.Nil(t, stored.ExpiresAt)
+			}
+		})
+	}
+}
+
+func TestGetOriginalURLExpiry(t *testing.T) {
+	now := time.Now()
+	future := now.Add(time.Hour)
+	past := now.Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		link    *model.Link
+		repoErr error
+		wantErr error
+	}{
+		{name: "срок не истёк", link: &model.Link{ShortURL: "b", OriginalURL: "https://example.com", ExpiresAt: &future}},
+		{name: "без срока", link: &model.Link{ShortURL: "b", OriginalURL: "https://example.com"}},
+		{name: "срок истёк", link: &model.Link{ShortURL: "b", OriginalURL: "https://example.com", ExpiresAt: &past}, wantErr: service.ErrLinkExpired},
+		{name: "удалена после истечения", link: &model.Link{ShortURL: "b", OriginalURL: "https://example.com", ExpiresAt: &past, DeletedAt: &past}, wantErr: service.ErrLinkDeleted},
+		{name: "не найдена", repoErr: repository.ErrLinkNotFound, wantErr: repository.ErrLinkNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockRepo := mockService.NewMockSwapRepository(ctrl)
+			svc := newMockedService(mockRepo, zap.NewNop())
+			mockRepo.EXPECT().GetOriginalURL(gomock.Any(), "b").Return(tt.link, tt.repoErr)
+
+			link, err := svc.GetOriginalURL(context.Background(), "b")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, link)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "https://example.com", link.OriginalURL)
+		})
+	}
+}
+
+func TestSweepExpired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mockService.NewMockSwapRepository(ctrl)
+	core, logs := observer.New(zap.InfoLevel)
+	svc := newMockedService(mockRepo, zap.New(core))
+
+	// Нулевой интервал отключает очистку
+	svc.SweepExpired(context.Background(), 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Удалённые ссылки считаются по каждому проходу, ошибка не останавливает очистку
+	results := []struct {
+		deleted int64
+		err     error
+	}{{3, nil}, {0, assert.AnError}, {2, nil}}
+	calls := 0
+	mockRepo.EXPECT().DeleteExpired(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, now time.Time) (int64, error) {
+			calls++
+			if calls == len(results) {
+				cancel()
+			}
+			if calls > len(results) {
+				return 0, nil
+			}
+			return results[calls-1].deleted, results[calls-1].err
+		}).
+		MinTimes(len(results))
+
+	svc.SweepExpired(ctx, time.Millisecond)
+
+	var counts []int64
+	for _, entry := range logs.FilterMessage("expired links deleted").All() {
+		counts = append(counts, entry.ContextMap()["count"].(int64))
+	}
+	assert.Equal(t, []int64{3, 2}, counts)
+	assert.Equal(t, 1, logs.FilterMessage("error deleting expired links").Len())
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+	"urlShortener/internal/model"
+	"urlShortener/internal/repository"
+)
+
+//go:generate mockgen -source=keys.go -destination=../../mocks/keys_mock.go
+
+const (
+	keyPrefix       = "sk_"
+	keyRandomBytes  = 32
+	keyPrefixLength = 8
+)
+
+type KeyRepository interface {
+	CreateKey(ctx context.Context, key *model.APIKey) error
+	GetKeyByHash(ctx context.Context, hash string) (*model.APIKey, error)
+	ListKeys(ctx context.Context) ([]model.APIKey, error)
+	RevokeKey(ctx context.Context, id int, now time.Time) error
+}
+
+type KeyServiceInterface interface {
+	CreateKey(ctx context.Context, name string) (*model.KeyResponse, error)
+	ListKeys(ctx context.Context) ([]model.APIKey, error)
+	RevokeKey(ctx context.Context, id int) error
+	Authenticate(ctx context.Context, rawKey string) (*model.APIKey, error)
+}
+
+type KeyService struct {
+	repository repository.KeyRepository
+	logger     *zap.Logger
+}
+
+type KeyDeps struct {
+	Repository repository.KeyRepository
+	Logger     *zap.Logger
+}
+
+func NewKeyService(deps KeyDeps) *KeyService {
+	return &KeyService{
+		repository: deps.Repository,
+		logger:     deps.Logger,
+	}
+}
+
+func (s *KeyService) CreateKey(ctx context.Context, name string) (*model.KeyResponse, error) {
+	if name == "" {
+		return nil, ErrEmptyKeyName
+	}
+
+	buf := make([]byte, keyRandomBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	rawKey := keyPrefix + base64.RawURLEncoding.EncodeToString(buf)
+
+	key, err := s.ImportKey(ctx, name, rawKey)
+	if err != nil {
+		return nil, err
+	}
+	return &model.KeyResponse{APIKey: *key, Key: rawKey}, nil
+}
+
+// ImportKey stores an externally supplied raw key, e.g. one loaded from config.
+// A key that is already stored is returned as is, so a revoked one stays revoked.
+// Links are owned by key name, so a name held by another live key is refused.
+func (s *KeyService) ImportKey(ctx context.Context, name string, rawKey string) (*model.APIKey, error) {
+	key := &model.APIKey{
+		Name:      name,
+		Prefix:    rawKey[:min(keyPrefixLength, len(rawKey))],
+		Hash:      HashKey(rawKey),
+		CreatedAt: time.Now(),
+	}
+	if err := s.repository.CreateKey(ctx, key); err != nil {
+		// A re-imported key clashes on its name too, whichever one the store reports.
+		if errors.Is(err, repository.ErrKeyExists) || errors.Is(err, repository.ErrKeyNameTaken) {
+			stored, getErr := s.repository.GetKeyByHash(ctx, key.Hash)
+			if getErr == nil {
+				return stored, nil
+			}
+			if !errors.Is(getErr, repository.ErrKeyNotFound) {
+				return nil, getErr
+			}
+			return nil, ErrKeyNameTaken
+		}
+		s.logger.Error("error creating API key", zap.String("name", name), zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("API key created", zap.Int("id", key.ID), zap.String("name", name))
+	return key, nil
+}
+
+func (s *KeyService) ListKeys(ctx context.Context) ([]model.APIKey, error) {
+	return s.repository.ListKeys(ctx)
+}
+
+func (s *KeyService) RevokeKey(ctx context.Context, id int) error {
+	if err := s.repository.RevokeKey(ctx, id, time.Now()); err != nil {
+		return err
+	}
+	s.logger.Info("API key revoked", zap.Int("id", id))
+	return nil
+}
+
+func (s *KeyService) Authenticate(ctx context.Context, rawKey string) (*model.APIKey, error) {
+	if rawKey == "" {
+		return nil, ErrUnauthorized
+	}
+
+	key, err := s.repository.GetKeyByHash(ctx, HashKey(rawKey))
+	if err != nil {
+		if err == repository.ErrKeyNotFound {
+			return nil, ErrUnauthorized
+		}
+		return nil, err
+	}
+	if key.RevokedAt != nil {
+		return nil, ErrUnauthorized
+	}
+	return key, nil
+}
+
+// ConfigKey splits an API_KEYS entry into the key name and the raw key. An
+// entry is either "name:key" or a bare key, which is named after its hash so
+// every config key owns its own links.
+func ConfigKey(entry string) (name string, rawKey string) {
+	if name, rawKey, found := strings.Cut(entry, ":"); found && name != "" {
+		return name, rawKey
+	}
+	return "config-" + HashKey(entry)[:keyPrefixLength], entry
+}
+
+// HashKey is what gets stored instead of the key itself. Keys are random
+// and long, so a plain SHA-256 is enough.
+func HashKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
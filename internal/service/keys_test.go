@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"testing"
+	"urlShortener/internal/repository"
+)
+
+func TestImportKeyTwice(t *testing.T) {
+	svc := NewKeyService(KeyDeps{Repository: repository.NewKeyStorage(zap.NewNop()), Logger: zap.NewNop()})
+	ctx := context.Background()
+
+	first, err := svc.ImportKey(ctx, "config", "sk_config_key")
+	require.NoError(t, err)
+
+	// Ключ из конфигурации при перезапуске не дублируется, отзыв сохраняется
+	require.NoError(t, svc.RevokeKey(ctx, first.ID))
+	second, err := svc.ImportKey(ctx, "config", "sk_config_key")
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, second.ID)
+	assert.NotNil(t, second.RevokedAt)
+
+	keys, _ := svc.ListKeys(ctx)
+	assert.Len(t, keys, 1)
+
+	_, err = svc.Authenticate(ctx, "sk_config_key")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestCreateKeyUniqueName(t *testing.T) {
+	svc := NewKeyService(KeyDeps{Repository: repository.NewKeyStorage(zap.NewNop()), Logger: zap.NewNop()})
+	ctx := context.Background()
+
+	first, err := svc.CreateKey(ctx, "marketing")
+	require.NoError(t, err)
+
+	// Второй ключ с тем же именем получил бы доступ к чужим ссылкам
+	_, err = svc.CreateKey(ctx, "marketing")
+	assert.ErrorIs(t, err, ErrKeyNameTaken)
+	_, err = svc.ImportKey(ctx, "marketing", "sk_other_key")
+	assert.ErrorIs(t, err, ErrKeyNameTaken)
+
+	// Ротация: после отзыва старого ключа имя свободно
+	require.NoError(t, svc.RevokeKey(ctx, first.ID))
+	_, err = svc.CreateKey(ctx, "marketing")
+	assert.NoError(t, err)
+}
+
+func TestConfigKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		entry    string
+		wantName string
+		wantKey  string
+	}{
+		{name: "с именем", entry: "marketing:sk_abc", wantName: "marketing", wantKey: "sk_abc"},
+		{name: "без имени", entry: "sk_abc", wantName: "config-" + HashKey("sk_abc")[:8], wantKey: "sk_abc"},
+		{name: "пустое имя", entry: ":sk_abc", wantName: "config-" + HashKey(":sk_abc")[:8], wantKey: ":sk_abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, rawKey := ConfigKey(tt.entry)
+			assert.Equal(t, tt.wantName, name)
+			assert.Equal(t, tt.wantKey, rawKey)
+		})
+	}
+
+	// Разные ключи без имени не делят одного владельца
+	first, _ := ConfigKey("sk_first")
+	second, _ := ConfigKey("sk_second")
+	assert.NotEqual(t, first, second)
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"go.uber.org/zap"
+	"strconv"
+	"strings"
+	"time"
+	"urlShortener/internal/model"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 1000
+)
+
+// ListLinks returns a page of live links owned by req.Owner, newest first
+// unless req.Sort is "created_at".
+func (s *ShortenerService) ListLinks(ctx context.Context, req model.ListRequest) (*model.LinkPage, error) {
+	query := model.ListQuery{
+		Owner: req.Owner,
+		Host:  req.Host,
+		Limit: req.Limit,
+	}
+
+	switch req.Sort {
+	case "", "-created_at":
+		query.Desc = true
+	case "created_at":
+	default:
+		return nil, fmt.Errorf("%w: sort must be created_at or -created_at", ErrInvalidListQuery)
+	}
+
+	if query.Limit == 0 {
+		query.Limit = defaultListLimit
+	}
+	if query.Limit < 0 || query.Limit > maxListLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidListQuery, maxListLimit)
+	}
+
+	var err error
+	if query.CreatedAfter, err = parseListTime("created_after", req.CreatedAfter); err != nil {
+		return nil, err
+	}
+	if query.CreatedBefore, err = parseListTime("created_before", req.CreatedBefore); err != nil {
+		return nil, err
+	}
+	if req.Cursor != "" {
+		if query.After, err = decodeCursor(req.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	// One extra row tells whether there is a next page.
+	limit := query.Limit
+	query.Limit++
+	links, err := s.repository.ListLinks(ctx, query)
+	if err != nil {
+		s.logger.Error("error listing links", zap.String("owner", req.Owner), zap.Error(err))
+		return nil, err
+	}
+
+	page := &model.LinkPage{Links: links}
+	if len(links) > limit {
+		page.Links = links[:limit]
+		last := page.Links[limit-1]
+		page.NextCursor = encodeCursor(model.LinkCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	return page, nil
+}
+
+func parseListTime(name string, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s must be an RFC 3339 timestamp", ErrInvalidListQuery, name)
+	}
+	return &t, nil
+}
+
+func encodeCursor(cursor model.LinkCursor) string {
+	raw := strconv.FormatInt(cursor.CreatedAt.UnixMicro(), 10) + ":" + strconv.Itoa(cursor.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(value string) (*model.LinkCursor, error) {
+	invalid := fmt.Errorf("%w: malformed cursor", ErrInvalidListQuery)
+
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, invalid
+	}
+	micros, id, found := strings.Cut(string(raw), ":")
+	if !found {
+		return nil, invalid
+	}
+
+	createdAt, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return nil, invalid
+	}
+	linkID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, invalid
+	}
+	return &model.LinkCursor{CreatedAt: time.UnixMicro(createdAt).UTC(), ID: linkID}, nil
+}
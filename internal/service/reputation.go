@@ -0,0 +1,88 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+type reputationRequest struct {
+	URL string `json:"url"`
+}
+
+type reputationResponse struct {
+	Blocked bool   `json:"blocked"`
+	Reason  string `json:"reason"`
+}
+
+// ReputationScreener asks an external service about every new URL. The
+// service receives POST {"url": "..."} and answers {"blocked": bool, "reason": "..."}.
+// When the service is unreachable the URL is let through unless failClosed is set.
+type ReputationScreener struct {
+	endpoint   string
+	client     *http.Client
+	failClosed bool
+	logger     *zap.Logger
+}
+
+func NewReputationScreener(endpoint string, timeout time.Duration, failClosed bool, logger *zap.Logger) *ReputationScreener {
+	return &ReputationScreener{
+		endpoint:   endpoint,
+		client:     &http.Client{Timeout: timeout},
+		failClosed: failClosed,
+		logger:     logger,
+	}
+}
+
+func (s *ReputationScreener) Screen(ctx context.Context, url string) error {
+	verdict, err := s.lookup(ctx, url)
+	if err != nil {
+		if s.failClosed {
+			return err
+		}
+		s.logger.Warn("reputation service unavailable, allowing URL", zap.String("url", url), zap.Error(err))
+		return nil
+	}
+
+	if verdict.Blocked {
+		message := "URL is flagged by the reputation service"
+		if verdict.Reason != "" {
+			message += ": " + verdict.Reason
+		}
+		return &BlockedError{Reason: ReasonReputation, Message: message}
+	}
+	return nil
+}
+
+func (s *ReputationScreener) lookup(ctx context.Context, url string) (*reputationResponse, error) {
+	body, err := json.Marshal(reputationRequest{URL: url})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("reputation service returned %s", resp.Status)
+	}
+
+	var verdict reputationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&verdict); err != nil {
+		return nil, fmt.Errorf("decoding reputation response: %w", err)
+	}
+	return &verdict, nil
+}
@@ -0,0 +1,51 @@
+package service
+
+import "context"
+
+// Reasons a URL is blocked, returned to clients as BlockedError.Reason.
+const (
+	ReasonBlockedDomain  = "blocked_domain"
+	ReasonBlockedPattern = "blocked_pattern"
+	ReasonReputation     = "reputation"
+)
+
+// URLScreener decides whether a normalized URL may be shortened or
+// followed. A rejection is reported as a *BlockedError, any other error
+// means the screener could not decide.
+type URLScreener interface {
+	Screen(ctx context.Context, url string) error
+}
+
+// BlockedError explains why a URL was rejected by a screener.
+type BlockedError struct {
+	Reason  string
+	Message string
+}
+
+func (e *BlockedError) Error() string {
+	return ErrURLBlocked.Error() + ": " + e.Message
+}
+
+func (e *BlockedError) Unwrap() error {
+	return ErrURLBlocked
+}
+
+// ScreenerChain runs screeners in order and stops at the first rejection.
+type ScreenerChain []URLScreener
+
+func (c ScreenerChain) Screen(ctx context.Context, url string) error {
+	for _, screener := range c {
+		if err := screener.Screen(ctx, url); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// screen runs screener over url, a nil screener lets everything through.
+func screen(ctx context.Context, screener URLScreener, url string) error {
+	if screener == nil {
+		return nil
+	}
+	return screener.Screen(ctx, url)
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"urlShortener/internal/model"
+)
+
+func writeBlocklist(t *testing.T, path, content string, modTime time.Time) {
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestBlocklistScreener(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	now := time.Now()
+	writeBlocklist(t, path, "# фишинг\nevil.example\nпример.рф\n/\\.exe$/\n", now)
+
+	screener, err := NewBlocklistScreener(path, zap.NewNop())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// Домен и его поддомены заблокированы, соседние домены — нет
+	var blocked *BlockedError
+	assert.ErrorAs(t, screener.Screen(ctx, "https://evil.example/login"), &blocked)
+	assert.Equal(t, ReasonBlockedDomain, blocked.Reason)
+	assert.ErrorIs(t, screener.Screen(ctx, "https://login.evil.example/"), ErrURLBlocked)
+	assert.ErrorIs(t, screener.Screen(ctx, "https://xn--e1afmkfd.xn--p1ai/"), ErrURLBlocked)
+	assert.NoError(t, screener.Screen(ctx, "https://notevil.example/"))
+
+	// Регулярное выражение применяется ко всему URL
+	assert.ErrorAs(t, screener.Screen(ctx, "https://files.example/setup.exe"), &blocked)
+	assert.Equal(t, ReasonBlockedPattern, blocked.Reason)
+
+	// Изменённый файл перечитывается
+	writeBlocklist(t, path, "files.example\n", now.Add(time.Second))
+	reloaded, err := screener.Reload()
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+	assert.NoError(t, screener.Screen(ctx, "https://evil.example/login"))
+	assert.ErrorIs(t, screener.Screen(ctx, "https://files.example/"), ErrURLBlocked)
+
+	// Файл с ошибкой не заменяет действующий список
+	writeBlocklist(t, path, "/([/\n", now.Add(2*time.Second))
+	_, err = screener.Reload()
+	assert.Error(t, err)
+	assert.ErrorIs(t, screener.Screen(ctx, "https://files.example/"), ErrURLBlocked)
+}
+
+func TestReputationScreener(t *testing.T) {
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req reputationRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch req.URL {
+		case "https://phish.example/":
+			json.NewEncoder(w).Encode(reputationResponse{Blocked: true, Reason: "phishing"})
+		case "https://broken.example/":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			json.NewEncoder(w).Encode(reputationResponse{})
+		}
+	}))
+	defer stub.Close()
+
+	ctx := context.Background()
+	screener := NewReputationScreener(stub.URL, time.Second, false, zap.NewNop())
+
+	var blocked *BlockedError
+	assert.ErrorAs(t, screener.Screen(ctx, "https://phish.example/"), &blocked)
+	assert.Equal(t, ReasonReputation, blocked.Reason)
+	assert.NoError(t, screener.Screen(ctx, "https://example.com/"))
+
+	// Недоступный сервис пропускает URL, если не требуется обратное
+	assert.NoError(t, screener.Screen(ctx, "https://broken.example/"))
+	strict := NewReputationScreener(stub.URL, time.Second, true, zap.NewNop())
+	err := strict.Screen(ctx, "https://broken.example/")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrURLBlocked)
+}
+
+func TestScreenedLinks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	now := time.Now()
+	writeBlocklist(t, path, "evil.example\n", now)
+	blocklist, err := NewBlocklistScreener(path, zap.NewNop())
+	require.NoError(t, err)
+
+	svc := newTestService()
+	svc.screener = ScreenerChain{blocklist}
+	svc.redirects = blocklist
+	ctx := context.Background()
+
+	_, err = svc.CreateShortURL(ctx, model.Request{URL: "https://EVIL.example/login"})
+	assert.ErrorIs(t, err, ErrURLBlocked)
+
+	resp, err := svc.CreateShortURL(ctx, model.Request{URL: "https://later.example/"})
+	require.NoError(t, err)
+	code := resp.URL[len(svc.shortLink("")):]
+
+	// Ссылка на домен, заблокированный после создания, перестаёт открываться
+	writeBlocklist(t, path, "evil.example\nlater.example\n", now.Add(time.Second))
+	_, err = blocklist.Reload()
+	require.NoError(t, err)
+	_, err = svc.GetOriginalURL(ctx, code)
+	assert.ErrorIs(t, err, ErrURLBlocked)
+}
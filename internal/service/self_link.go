@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"urlShortener/internal/repository"
+)
+
+const (
+	SelfLinksReject  = "reject"
+	SelfLinksResolve = "resolve"
+)
+
+// selfHosts are the normalized hosts short links are served from:
+// the host of the base URL and every alias domain.
+func selfHosts(baseURL string, aliasDomains []string) map[string]struct{} {
+	hosts := make(map[string]struct{})
+	candidates := []string{baseURL}
+	for _, domain := range aliasDomains {
+		candidates = append(candidates, "http://"+strings.TrimSpace(domain))
+	}
+	for _, candidate := range candidates {
+		normalized, err := NormalizeURL(candidate, URLPolicy{})
+		if err != nil {
+			continue
+		}
+		if u, err := url.Parse(normalized); err == nil {
+			hosts[u.Host] = struct{}{}
+		}
+	}
+	return hosts
+}
+
+// resolveSelfLink keeps destinations from pointing back at this shortener.
+// With SELF_LINKS=resolve a URL of one of our short links is replaced by
+// that link's target, following at most SelfLinkMaxDepth hops. Any other
+// URL on our hosts, and every one with SELF_LINKS=reject, is refused.
+func (s *ShortenerService) resolveSelfLink(ctx context.Context, target string) (string, error) {
+	seen := make(map[string]struct{})
+	for {
+		code, own := s.selfCode(target)
+		if !own {
+			return target, nil
+		}
+		if code == "" || s.config.SelfLinks != SelfLinksResolve {
+			return "", &URLError{Reason: ReasonSelfReference, Message: "URL points back at this shortener"}
+		}
+		if _, loop := seen[code]; loop || len(seen) >= s.config.SelfLinkMaxDepth {
+			return "", &URLError{Reason: ReasonRedirectLoop,
+				Message: fmt.Sprintf("short link chain is looped or longer than %d hops", s.config.SelfLinkMaxDepth)}
+		}
+		seen[code] = struct{}{}
+
+		link, err := s.repository.GetOriginalURL(ctx, code)
+		if err != nil {
+			if errors.Is(err, repository.ErrLinkNotFound) {
+				return "", &URLError{Reason: ReasonSelfReference, Message: fmt.Sprintf("short link %q does not exist", code)}
+			}
+			return "", err
+		}
+		if link.DeletedAt != nil {
+			return "", &URLError{Reason: ReasonSelfReference, Message: fmt.Sprintf("short link %q does not exist", code)}
+		}
+		target = link.OriginalURL
+	}
+}
+
+// selfCode reports whether a normalized URL is served by this shortener and,
+// if it addresses a short link, that link's code.
+func (s *ShortenerService) selfCode(target string) (string, bool) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", false
+	}
+	if _, own := s.selfHosts[u.Host]; !own {
+		return "", false
+	}
+
+	prefix := ""
+	if base, err := url.Parse(s.config.BaseURL()); err == nil {
+		prefix = strings.TrimRight(base.Path, "/")
+	}
+	rest, found := strings.CutPrefix(u.Path, prefix+"/")
+	if !found || rest == "" || strings.Contains(rest, "/") {
+		return "", true
+	}
+	return rest, true
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+	"urlShortener/internal/initialize"
+	"urlShortener/internal/model"
+	"urlShortener/internal/repository"
+	"urlShortener/internal/utils"
+)
+
+func newSelfLinkService(mode string) *ShortenerService {
+	logger := zap.NewNop()
+	generator, _ := utils.NewCodeGenerator("base62", "")
+	return NewShortenerService(Deps{
+		Repository:      repository.NewURLStorage(logger),
+		ClickRepository: repository.NewClickStorage(16, logger),
+		Generator:       generator,
+		Config: &initialize.Config{
+			PublicBaseURL:    "https://sho.rt/l",
+			AliasDomains:     []string{"go.sho.rt"},
+			SelfLinks:        mode,
+			SelfLinkMaxDepth: 2,
+			RedirectType:     302,
+		},
+		Logger: logger,
+	})
+}
+
+func urlReason(t *testing.T, err error) string {
+	t.Helper()
+	var urlErr *URLError
+	require.ErrorAs(t, err, &urlErr)
+	return urlErr.Reason
+}
+
+func TestSelfLinksReject(t *testing.T) {
+	svc := newSelfLinkService(SelfLinksReject)
+	ctx := context.Background()
+
+	for _, target := range []string{"https://SHO.RT/l/abc", "http://go.sho.rt:80/l/abc", "https://sho.rt/l/api/links"} {
+		_, err := svc.CreateShortURL(ctx, model.Request{URL: target})
+		assert.Equal(t, ReasonSelfReference, urlReason(t, err), target)
+	}
+
+	// Тот же хост с другим портом и чужие домены допустимы
+	_, err := svc.CreateShortURL(ctx, model.Request{URL: "https://sho.rt:8443/l/abc"})
+	assert.NoError(t, err)
+	_, err = svc.CreateShortURL(ctx, model.Request{URL: "https://example.com/l/abc"})
+	assert.NoError(t, err)
+}
+
+func TestSelfLinksResolve(t *testing.T) {
+	svc := newSelfLinkService(SelfLinksResolve)
+	ctx := context.Background()
+
+	first, err := svc.CreateShortURL(ctx, model.Request{URL: "https://example.com/final", Alias: "first"})
+	require.NoError(t, err)
+
+	// Ссылка на собственную короткую ссылку сохраняется с конечным адресом
+	_, err = svc.CreateShortURL(ctx, model.Request{URL: first.URL, Alias: "second"})
+	require.NoError(t, err)
+	link, err := svc.GetOriginalURL(ctx, "second")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/final", link.OriginalURL)
+
+	_, err = svc.CreateShortURL(ctx, model.Request{URL: "https://go.sho.rt/l/missing"})
+	assert.Equal(t, ReasonSelfReference, urlReason(t, err))
+
+	// Цепочки из ранее сохранённых ссылок ограничены по глубине
+	storage := svc.repository
+	for i, pair := range [][2]string{{"hop1", "https://sho.rt/l/hop2"}, {"hop2", "https://sho.rt/l/hop3"}, {"hop3", "https://example.com/"}} {
+		require.NoError(t, storage.CreateShortURL(ctx, &model.Link{ID: 100 + i, ShortURL: pair[0], OriginalURL: pair[1], CreatedAt: time.Now()}))
+	}
+	_, err = svc.CreateShortURL(ctx, model.Request{URL: "https://sho.rt/l/hop1"})
+	assert.Equal(t, ReasonRedirectLoop, urlReason(t, err))
+
+	// Ссылка, изменённая на саму себя, сохраняет прежний конечный адрес
+	target := "https://sho.rt/l/first"
+	_, err = svc.UpdateLink(ctx, "first", model.UpdateRequest{URL: &target})
+	assert.NoError(t, err)
+	link, err = svc.GetOriginalURL(ctx, "first")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/final", link.OriginalURL)
+
+	// Петля из сохранённых ссылок
+	require.NoError(t, storage.CreateShortURL(ctx, &model.Link{ID: 200, ShortURL: "loopA", OriginalURL: "https://sho.rt/l/loopB", CreatedAt: time.Now()}))
+	require.NoError(t, storage.CreateShortURL(ctx, &model.Link{ID: 201, ShortURL: "loopB", OriginalURL: "https://sho.rt/l/loopA", CreatedAt: time.Now()}))
+	_, err = svc.CreateShortURL(ctx, model.Request{URL: "https://sho.rt/l/loopA"})
+	assert.Equal(t, ReasonRedirectLoop, urlReason(t, err))
+}
@@ -2,7 +2,11 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"go.uber.org/zap"
+	"net/http"
+	"time"
 	"urlShortener/internal/initialize"
 	"urlShortener/internal/model"
 	"urlShortener/internal/repository"
@@ -12,47 +16,96 @@ import (
 //go:generate mockgen -source=shortener.go -destination=../../mocks/shortener_mock.go
 
 type SwapRepository interface {
-	CreateShortURL(ctx context.Context, id int, shortURL string, originalURL string) error
-	GetOriginalURL(ctx context.Context, shortURL string) (string, error)
-	CheckDublicate(ctx context.Context, originalURL string) (string, error)
+	CreateShortURL(ctx context.Context, link *model.Link) error
+	CreateShortURLs(ctx context.Context, links []*model.Link) ([]int, error)
+	GetOriginalURL(ctx context.Context, shortURL string) (*model.Link, error)
+	GetOriginalURLs(ctx context.Context, shortURLs []string) (map[string]model.Link, error)
+	CheckDublicate(ctx context.Context, originalURL string, owner string) (string, error)
 	GetNextID(ctx context.Context) (int, error)
+	GetNextIDs(ctx context.Context, n int) ([]int, error)
+	ShortURLExists(ctx context.Context, shortURL string) (bool, error)
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+	UpdateLink(ctx context.Context, link *model.Link) error
+	DeleteLink(ctx context.Context, shortURL string, now time.Time) error
+	ListLinks(ctx context.Context, query model.ListQuery) ([]model.Link, error)
+}
+
+type ClickRepository interface {
+	SaveClicks(ctx context.Context, clicks []model.Click) error
+	GetStats(ctx context.Context, shortURL string) (*model.Stats, error)
 }
 
 type ShortenerServiceInterface interface {
-	CreateShortURL(ctx context.Context, url string) (*model.Response, error)
-	GetOriginalURL(ctx context.Context, url string) (*model.Response, error)
+	CreateShortURL(ctx context.Context, req model.Request) (*model.Response, error)
+	CreateShortURLs(ctx context.Context, reqs []model.Request) ([]BatchResult, error)
+	GetOriginalURL(ctx context.Context, url string) (*model.Link, error)
+	ExpandURLs(ctx context.Context, codes []string) (map[string]model.ExpandResult, error)
+	UpdateLink(ctx context.Context, url string, req model.UpdateRequest) (*model.Response, error)
+	DeleteLink(ctx context.Context, url string, owner string) error
+	ListLinks(ctx context.Context, req model.ListRequest) (*model.LinkPage, error)
+	RecordClick(click model.Click)
+	GetStats(ctx context.Context, url string, owner string) (*model.Stats, error)
 }
 
 type ShortenerService struct {
 	repository repository.SwapRepository
+	clicks     repository.ClickRepository
+	events     chan model.Click
+	generator  utils.CodeGenerator
+	screener   URLScreener
+	redirects  URLScreener
+	selfHosts  map[string]struct{}
 	config     *initialize.Config
 	logger     *zap.Logger
 }
 
+// Deps wires the service. Screener vets URLs of new and updated links,
+// RedirectScreener vets the stored URL on every resolve, so it should not
+// leave the process. Either may be nil.
 type Deps struct {
-	Repository repository.SwapRepository
-	Config     *initialize.Config
-	Logger     *zap.Logger
+	Repository       repository.SwapRepository
+	ClickRepository  repository.ClickRepository
+	Generator        utils.CodeGenerator
+	Screener         URLScreener
+	RedirectScreener URLScreener
+	Config           *initialize.Config
+	Logger           *zap.Logger
 }
 
 func NewShortenerService(deps Deps) *ShortenerService {
 	return &ShortenerService{
 		repository: deps.Repository,
+		clicks:     deps.ClickRepository,
+		events:     make(chan model.Click, deps.Config.ClickBuffer),
+		generator:  deps.Generator,
+		screener:   deps.Screener,
+		redirects:  deps.RedirectScreener,
+		selfHosts:  selfHosts(deps.Config.BaseURL(), deps.Config.AliasDomains),
 		config:     deps.Config,
 		logger:     deps.Logger,
 	}
 }
 
-func (s *ShortenerService) CreateShortURL(ctx context.Context, url string) (*model.Response, error) {
-	existURL, err := s.repository.CheckDublicate(ctx, url)
-	if err != nil && err != repository.ErrLinkNotFound {
+func (s *ShortenerService) CreateShortURL(ctx context.Context, req model.Request) (*model.Response, error) {
+	expiresAt, err := s.prepare(ctx, &req)
+	if err != nil {
 		return nil, err
 	}
 
-	if existURL != "" {
-		return &model.Response{
-			URL: "http://" + s.config.HTTPHost + ":" + s.config.HTTPPort + "/" + existURL,
-		}, nil
+	if req.Alias != "" {
+		return s.createAlias(ctx, req, expiresAt)
+	}
+
+	if s.shared(req, expiresAt) {
+		existURL, err := s.existing(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if existURL != "" {
+			return &model.Response{
+				URL: s.shortLink(existURL),
+			}, nil
+		}
 	}
 
 	nextID, err := s.repository.GetNextID(ctx)
@@ -61,25 +114,322 @@ func (s *ShortenerService) CreateShortURL(ctx context.Context, url string) (*mod
 		return nil, err
 	}
 
-	shortURL := utils.GenShort(nextID)
-	err = s.repository.CreateShortURL(ctx, nextID, shortURL, url)
+	// Skip IDs whose generated code is already held by an alias.
+	shortURL := s.generator.Encode(nextID)
+	for {
+		taken, err := s.repository.ShortURLExists(ctx, shortURL)
+		if err != nil {
+			s.logger.Error("error checking short url", zap.Error(err))
+			return nil, err
+		}
+		if !taken {
+			break
+		}
+
+		nextID, err = s.repository.GetNextID(ctx)
+		if err != nil {
+			s.logger.Error("error getting next id", zap.Error(err))
+			return nil, err
+		}
+		shortURL = s.generator.Encode(nextID)
+	}
+
+	err = s.repository.CreateShortURL(ctx, &model.Link{
+		ID:           nextID,
+		ShortURL:     shortURL,
+		OriginalURL:  req.URL,
+		RedirectType: req.RedirectType,
+		ExpiresAt:    expiresAt,
+		Owner:        req.Owner,
+		CreatedAt:    creationTime(),
+	})
 	if err != nil {
 		s.logger.Error("error creating short url", zap.Error(err))
 		return nil, err
 	}
 
 	return &model.Response{
-		URL: "http://" + s.config.HTTPHost + ":" + s.config.HTTPPort + "/" + shortURL,
+		URL:       s.shortLink(shortURL),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// prepare validates req and rewrites its URL to the form that is stored:
+// normalized, with self links resolved and cleared by the screener.
+func (s *ShortenerService) prepare(ctx context.Context, req *model.Request) (*time.Time, error) {
+	if req.RedirectType != 0 && !IsRedirectType(req.RedirectType) {
+		return nil, ErrInvalidRedirectType
+	}
+	if req.Alias != "" {
+		if err := ValidateAlias(req.Alias); err != nil {
+			return nil, err
+		}
+	}
+
+	// The normalized form is stored and deduplicated on, so spellings of the same URL share a link.
+	normalized, err := NormalizeURL(req.URL, s.urlPolicy())
+	if err != nil {
+		return nil, err
+	}
+	req.URL, err = s.resolveSelfLink(ctx, normalized)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := screen(ctx, s.screener, req.URL); err != nil {
+		s.logger.Warn("URL rejected by screener", zap.String("url", req.URL), zap.String("owner", req.Owner), zap.Error(err))
+		return nil, err
+	}
+
+	return expiry(req.TTL, req.ExpiresAt, time.Now())
+}
+
+// shared reports whether req may reuse an existing link. Only permanent
+// links are shared between requests, a link with an expiry is always new.
+func (s *ShortenerService) shared(req model.Request, expiresAt *time.Time) bool {
+	return req.Alias == "" && expiresAt == nil && s.dedupe(req)
+}
+
+// existing returns the code of the owner's permanent link to req.URL, or "" if there is none.
+func (s *ShortenerService) existing(ctx context.Context, req model.Request) (string, error) {
+	existURL, err := s.repository.CheckDublicate(ctx, req.URL, req.Owner)
+	if err != nil && err != repository.ErrLinkNotFound {
+		return "", err
+	}
+	return existURL, nil
+}
+
+func (s *ShortenerService) createAlias(ctx context.Context, req model.Request, expiresAt *time.Time) (*model.Response, error) {
+	taken, err := s.repository.ShortURLExists(ctx, req.Alias)
+	if err != nil {
+		s.logger.Error("error checking alias", zap.String("alias", req.Alias), zap.Error(err))
+		return nil, err
+	}
+	if taken {
+		return nil, ErrAliasTaken
+	}
+
+	nextID, err := s.repository.GetNextID(ctx)
+	if err != nil {
+		s.logger.Error("error getting next id", zap.Error(err))
+		return nil, err
+	}
+
+	err = s.repository.CreateShortURL(ctx, &model.Link{
+		ID:           nextID,
+		ShortURL:     req.Alias,
+		OriginalURL:  req.URL,
+		RedirectType: req.RedirectType,
+		ExpiresAt:    expiresAt,
+		Owner:        req.Owner,
+		CreatedAt:    creationTime(),
+	})
+	if errors.Is(err, repository.ErrShortURLExists) {
+		// Another request took the alias after the ShortURLExists check.
+		return nil, ErrAliasTaken
+	}
+	if err != nil {
+		s.logger.Error("error creating alias", zap.String("alias", req.Alias), zap.Error(err))
+		return nil, err
+	}
+
+	return &model.Response{
+		URL:       s.shortLink(req.Alias),
+		ExpiresAt: expiresAt,
 	}, nil
 }
 
-func (s *ShortenerService) GetOriginalURL(ctx context.Context, url string) (*model.Response, error) {
-	originalURL, err := s.repository.GetOriginalURL(ctx, url)
+// GetOriginalURL resolves a short code. A link stored without its own
+// redirect type inherits the configured default.
+func (s *ShortenerService) GetOriginalURL(ctx context.Context, url string) (*model.Link, error) {
+	link, err := s.repository.GetOriginalURL(ctx, url)
 	if err != nil {
 		s.logger.Error("error getting original url", zap.Error(err))
 		return nil, err
 	}
+
+	if link.DeletedAt != nil {
+		return nil, ErrLinkDeleted
+	}
+	if link.Expired(time.Now()) {
+		return nil, ErrLinkExpired
+	}
+	if err := screen(ctx, s.redirects, link.OriginalURL); err != nil {
+		return nil, err
+	}
+
+	if link.RedirectType == 0 {
+		link.RedirectType = s.config.RedirectType
+	}
+	if !IsRedirectType(link.RedirectType) {
+		link.RedirectType = http.StatusFound
+	}
+	return link, nil
+}
+
+// UpdateLink changes the target, redirect type or expiry of a live link.
+// Fields left out of req keep their current values.
+func (s *ShortenerService) UpdateLink(ctx context.Context, url string, req model.UpdateRequest) (*model.Response, error) {
+	link, err := s.repository.GetOriginalURL(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	if link.DeletedAt != nil {
+		return nil, ErrLinkDeleted
+	}
+	if !ownedBy(link, req.Owner) {
+		return nil, ErrForbidden
+	}
+
+	if req.URL != nil {
+		normalized, err := NormalizeURL(*req.URL, s.urlPolicy())
+		if err != nil {
+			return nil, err
+		}
+		link.OriginalURL, err = s.resolveSelfLink(ctx, normalized)
+		if err != nil {
+			return nil, err
+		}
+		if err := screen(ctx, s.screener, link.OriginalURL); err != nil {
+			s.logger.Warn("URL rejected by screener", zap.String("url", link.OriginalURL), zap.String("owner", req.Owner), zap.Error(err))
+			return nil, err
+		}
+	}
+
+	if req.RedirectType != nil {
+		if *req.RedirectType != 0 && !IsRedirectType(*req.RedirectType) {
+			return nil, ErrInvalidRedirectType
+		}
+		link.RedirectType = *req.RedirectType
+	}
+
+	if req.Permanent {
+		if req.TTL != "" || req.ExpiresAt != nil {
+			return nil, fmt.Errorf("%w: permanent cannot be combined with ttl or expires_at", ErrInvalidExpiry)
+		}
+		link.ExpiresAt = nil
+	} else if req.TTL != "" || req.ExpiresAt != nil {
+		link.ExpiresAt, err = expiry(req.TTL, req.ExpiresAt, time.Now())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repository.UpdateLink(ctx, link); err != nil {
+		s.logger.Error("error updating link", zap.String("short_url", url), zap.Error(err))
+		return nil, err
+	}
+
 	return &model.Response{
-		URL: originalURL,
-	}, err
+		URL:       s.shortLink(link.ShortURL),
+		ExpiresAt: link.ExpiresAt,
+	}, nil
+}
+
+func (s *ShortenerService) DeleteLink(ctx context.Context, url string, owner string) error {
+	link, err := s.repository.GetOriginalURL(ctx, url)
+	if err != nil {
+		return err
+	}
+	if link.DeletedAt != nil {
+		return repository.ErrLinkNotFound
+	}
+	if !ownedBy(link, owner) {
+		return ErrForbidden
+	}
+
+	if err := s.repository.DeleteLink(ctx, url, time.Now()); err != nil {
+		if err != repository.ErrLinkNotFound {
+			s.logger.Error("error deleting link", zap.String("short_url", url), zap.Error(err))
+		}
+		return err
+	}
+	return nil
+}
+
+// ownedBy reports whether owner may manage link. Anonymous callers (auth
+// disabled) and links created before ownership existed are not restricted.
+func ownedBy(link *model.Link, owner string) bool {
+	return owner == "" || link.Owner == "" || link.Owner == owner
+}
+
+func IsRedirectType(code int) bool {
+	switch code {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// dedupe reports whether req may reuse an existing link for the same URL.
+func (s *ShortenerService) dedupe(req model.Request) bool {
+	if req.Dedupe != nil {
+		return *req.Dedupe
+	}
+	return s.config.Dedupe
+}
+
+func (s *ShortenerService) urlPolicy() URLPolicy {
+	return URLPolicy{
+		Schemes:   s.config.URLSchemes,
+		SortQuery: s.config.URLSortQuery,
+	}
+}
+
+// creationTime is the creation timestamp of new links, truncated to what Postgres stores
+// so list cursors match across storage backends.
+func creationTime() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+func (s *ShortenerService) shortLink(code string) string {
+	return s.config.BaseURL() + "/" + code
+}
+
+// SweepExpired purges expired links every interval until ctx is done.
+// A non-positive interval disables the sweeper.
+func (s *ShortenerService) SweepExpired(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := s.repository.DeleteExpired(ctx, time.Now())
+			if err != nil {
+				s.logger.Error("error deleting expired links", zap.Error(err))
+				continue
+			}
+			if deleted > 0 {
+				s.logger.Info("expired links deleted", zap.Int64("count", deleted))
+			}
+		}
+	}
+}
+
+// expiry returns the absolute expiry requested either as a TTL or as a timestamp.
+func expiry(ttl string, expiresAt *time.Time, now time.Time) (*time.Time, error) {
+	if ttl != "" && expiresAt != nil {
+		return nil, fmt.Errorf("%w: ttl and expires_at are mutually exclusive", ErrInvalidExpiry)
+	}
+
+	if ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("%w: ttl must be a positive duration such as \"24h\"", ErrInvalidExpiry)
+		}
+		at := now.Add(d)
+		return &at, nil
+	}
+
+	if expiresAt != nil && !expiresAt.After(now) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidExpiry)
+	}
+	return expiresAt, nil
 }
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"strings"
+	"sync"
+	"testing"
+	"urlShortener/internal/initialize"
+	"urlShortener/internal/model"
+	"urlShortener/internal/repository"
+	"urlShortener/internal/utils"
+)
+
+func newTestService() *ShortenerService {
+	logger := zap.NewNop()
+	generator, _ := utils.NewCodeGenerator("base62", "")
+	return NewShortenerService(Deps{
+		Repository:      repository.NewURLStorage(logger),
+		ClickRepository: repository.NewClickStorage(16, logger),
+		Generator:       generator,
+		Config:          &initialize.Config{HTTPHost: "localhost", HTTPPort: "3000", RedirectType: 302, Dedupe: true},
+		Logger:          logger,
+	})
+}
+
+// 10k параллельных запросов должны получить уникальные коды без ошибок
+func TestCreateShortURLConcurrent(t *testing.T) {
+	const n = 10000
+	svc := newTestService()
+
+	var wg sync.WaitGroup
+	urls := make([]string, n)
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := svc.CreateShortURL(context.Background(), model.Request{URL: fmt.Sprintf("https://example.com/%d", i)})
+			errs[i] = err
+			if err == nil {
+				urls[i] = resp.URL
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[string]struct{}, n)
+	for i := 0; i < n; i++ {
+		require.NoError(t, errs[i])
+		_, dup := seen[urls[i]]
+		assert.False(t, dup, "duplicate short url %s", urls[i])
+		seen[urls[i]] = struct{}{}
+	}
+	assert.Len(t, seen, n)
+}
+
+// Гонка за один алиас: проигравшие получают ErrAliasTaken, а не ошибку хранилища
+func TestCreateAliasConcurrent(t *testing.T) {
+	const n = 32
+	svc := newTestService()
+
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = svc.CreateShortURL(context.Background(), model.Request{URL: fmt.Sprintf("https://example.com/%d", i), Alias: "sale"})
+		}(i)
+	}
+	wg.Wait()
+
+	created := 0
+	for _, err := range errs {
+		if err == nil {
+			created++
+			continue
+		}
+		assert.ErrorIs(t, err, ErrAliasTaken)
+	}
+	assert.Equal(t, 1, created)
+}
+
+func TestShortLinkBaseURL(t *testing.T) {
+	svc := newTestService()
+
+	// Без PUBLIC_BASE_URL используется адрес HTTP-сервера
+	resp, err := svc.CreateShortURL(context.Background(), model.Request{URL: "https://example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:3000/0", resp.URL)
+
+	// PUBLIC_BASE_URL с префиксом пути
+	svc.config.PublicBaseURL = "https://sho.rt/l/"
+	resp, err = svc.CreateShortURL(context.Background(), model.Request{URL: "https://example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://sho.rt/l/0", resp.URL)
+}
+
+func TestCreateShortURLDedupe(t *testing.T) {
+	svc := newTestService()
+	noDedupe := false
+
+	first, err := svc.CreateShortURL(context.Background(), model.Request{URL: "https://example.com"})
+	require.NoError(t, err)
+
+	// По умолчанию возвращается уже существующая ссылка
+	second, err := svc.CreateShortURL(context.Background(), model.Request{URL: "https://example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, first.URL, second.URL)
+
+	// dedupe: false создаёт отдельную ссылку
+	third, err := svc.CreateShortURL(context.Background(), model.Request{URL: "https://example.com", Dedupe: &noDedupe})
+	require.NoError(t, err)
+	assert.NotEqual(t, first.URL, third.URL)
+
+	// Разные написания одного URL дают ту же ссылку
+	spelled, err := svc.CreateShortURL(context.Background(), model.Request{URL: "HTTPS://Example.com:443"})
+	require.NoError(t, err)
+	assert.Equal(t, first.URL, spelled.URL)
+
+	// Политика по умолчанию из конфигурации
+	svc.config.Dedupe = false
+	fourth, err := svc.CreateShortURL(context.Background(), model.Request{URL: "https://example.com"})
+	require.NoError(t, err)
+	assert.NotEqual(t, first.URL, fourth.URL)
+	assert.NotEqual(t, third.URL, fourth.URL)
+}
+
+func TestListLinksPagination(t *testing.T) {
+	svc := newTestService()
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		_, err := svc.CreateShortURL(ctx, model.Request{URL: fmt.Sprintf("https://example.com/%d", i), Owner: "marketing"})
+		require.NoError(t, err)
+	}
+	_, err := svc.CreateShortURL(ctx, model.Request{URL: "https://other.org/", Owner: "marketing"})
+	require.NoError(t, err)
+	_, err = svc.CreateShortURL(ctx, model.Request{URL: "https://example.com/foreign", Owner: "sales"})
+	require.NoError(t, err)
+
+	// Постраничный обход ссылок владельца с фильтром по хосту
+	var codes []string
+	req := model.ListRequest{Owner: "marketing", Host: "example.com", Limit: 2, Sort: "created_at"}
+	for {
+		page, err := svc.ListLinks(ctx, req)
+		require.NoError(t, err)
+		for _, link := range page.Links {
+			assert.Equal(t, "marketing", link.Owner)
+			codes = append(codes, link.OriginalURL)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		req.Cursor = page.NextCursor
+	}
+	assert.Equal(t, []string{
+		"https://example.com/0", "https://example.com/1", "https://example.com/2",
+		"https://example.com/3", "https://example.com/4",
+	}, codes)
+
+	_, err = svc.ListLinks(ctx, model.ListRequest{Cursor: "%%%"})
+	assert.ErrorIs(t, err, ErrInvalidListQuery)
+}
+
+func TestLinkOwnership(t *testing.T) {
+	svc := newTestService()
+	ctx := context.Background()
+
+	resp, err := svc.CreateShortURL(ctx, model.Request{URL: "https://example.com", Owner: "marketing"})
+	require.NoError(t, err)
+	code := resp.URL[strings.LastIndex(resp.URL, "/")+1:]
+
+	// Другой владелец не может изменить или удалить ссылку
+	target := "https://example.org"
+	_, err = svc.UpdateLink(ctx, code, model.UpdateRequest{URL: &target, Owner: "sales"})
+	assert.ErrorIs(t, err, ErrForbidden)
+	assert.ErrorIs(t, svc.DeleteLink(ctx, code, "sales"), ErrForbidden)
+
+	assert.NoError(t, svc.DeleteLink(ctx, code, "marketing"))
+
+	// Дедупликация не отдаёт ссылки чужого владельца
+	other, err := svc.CreateShortURL(ctx, model.Request{URL: "https://example.net", Owner: "marketing"})
+	require.NoError(t, err)
+	foreign, err := svc.CreateShortURL(ctx, model.Request{URL: "https://example.net", Owner: "sales"})
+	require.NoError(t, err)
+	assert.NotEqual(t, other.URL, foreign.URL)
+}
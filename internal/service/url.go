@@ -0,0 +1,138 @@
+package service
+
+import (
+	"fmt"
+	"golang.org/x/net/idna"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const urlMaxLength = 2048
+
+// Reasons a URL is rejected, returned to clients as URLError.Reason.
+const (
+	ReasonMalformed        = "malformed"
+	ReasonTooLong          = "too_long"
+	ReasonRelative         = "relative_url"
+	ReasonSchemeNotAllowed = "scheme_not_allowed"
+	ReasonMissingHost      = "missing_host"
+	ReasonInvalidHost      = "invalid_host"
+	ReasonInvalidPort      = "invalid_port"
+	ReasonSelfReference    = "self_reference"
+	ReasonRedirectLoop     = "redirect_loop"
+)
+
+// defaultURLSchemes apply when the policy does not list any.
+var defaultURLSchemes = []string{"http", "https"}
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// URLError explains why a target URL was rejected. Reason is stable and
+// meant for machines, the message for people.
+type URLError struct {
+	Reason  string
+	Message string
+}
+
+func (e *URLError) Error() string {
+	return ErrInvalidURL.Error() + ": " + e.Message
+}
+
+func (e *URLError) Unwrap() error {
+	return ErrInvalidURL
+}
+
+// Malformed reports whether the URL could not be parsed at all, as opposed
+// to a well-formed URL that is not allowed.
+func (e *URLError) Malformed() bool {
+	return e.Reason == ReasonMalformed || e.Reason == ReasonTooLong
+}
+
+// URLPolicy is what a target URL has to satisfy to be shortened.
+type URLPolicy struct {
+	Schemes   []string
+	SortQuery bool
+}
+
+// NormalizeURL validates raw against policy and returns its canonical form:
+// lowercase scheme and host, IDN hosts in punycode, no default port, "/" for
+// an empty path and, if the policy asks for it, query parameters sorted by name.
+func NormalizeURL(raw string, policy URLPolicy) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", ErrEmptyURL
+	}
+	if len(raw) > urlMaxLength {
+		return "", &URLError{Reason: ReasonTooLong, Message: fmt.Sprintf("URL is longer than %d bytes", urlMaxLength)}
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", &URLError{Reason: ReasonMalformed, Message: "URL cannot be parsed"}
+	}
+	if u.Scheme == "" {
+		return "", &URLError{Reason: ReasonRelative, Message: "URL must be absolute"}
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	schemes := policy.Schemes
+	if len(schemes) == 0 {
+		schemes = defaultURLSchemes
+	}
+	if !slices.Contains(schemes, u.Scheme) {
+		return "", &URLError{Reason: ReasonSchemeNotAllowed, Message: fmt.Sprintf("scheme %q is not allowed", u.Scheme)}
+	}
+	if u.Opaque != "" || u.Host == "" {
+		return "", &URLError{Reason: ReasonMissingHost, Message: "URL must have a host"}
+	}
+
+	host, err := normalizeHost(u.Hostname())
+	if err != nil {
+		return "", err
+	}
+	port := u.Port()
+	if port != "" {
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return "", &URLError{Reason: ReasonInvalidPort, Message: fmt.Sprintf("port %q is out of range", port)}
+		}
+		if defaultPorts[u.Scheme] == port {
+			port = ""
+		}
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" {
+		host += ":" + port
+	}
+	u.Host = host
+
+	if u.Path == "" && u.RawPath == "" {
+		u.Path = "/"
+	}
+	if policy.SortQuery && u.RawQuery != "" {
+		// Encode orders parameters by name and keeps repeated values in order.
+		u.RawQuery = u.Query().Encode()
+	}
+	return u.String(), nil
+}
+
+func normalizeHost(host string) (string, error) {
+	host = strings.TrimSuffix(host, ".")
+	if host == "" {
+		return "", &URLError{Reason: ReasonMissingHost, Message: "URL must have a host"}
+	}
+	if strings.Contains(host, ":") {
+		// IPv6 literal, url.Parse has already checked the brackets.
+		return strings.ToLower(host), nil
+	}
+	ascii, err := idna.Lookup.ToASCII(host)
+	if err != nil {
+		return "", &URLError{Reason: ReasonInvalidHost, Message: fmt.Sprintf("host %q is not a valid domain name", host)}
+	}
+	return ascii, nil
+}
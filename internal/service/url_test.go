@@ -0,0 +1,59 @@
+package service
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		raw       string
+		sortQuery bool
+		want      string
+		reason    string
+	}{
+		{name: "lowercase scheme and host", raw: "HTTPS://Example.COM/Path", want: "https://example.com/Path"},
+		{name: "default port", raw: "http://example.com:80/a", want: "http://example.com/a"},
+		{name: "other port", raw: "https://example.com:8443/a", want: "https://example.com:8443/a"},
+		{name: "empty path", raw: "https://example.com", want: "https://example.com/"},
+		{name: "idn", raw: "https://пример.рф/путь", want: "https://xn--e1afmkfd.xn--p1ai/%D0%BF%D1%83%D1%82%D1%8C"},
+		{name: "ipv6", raw: "http://[2001:DB8::1]:80/", want: "http://[2001:db8::1]/"},
+		{name: "query kept", raw: "https://example.com/?b=2&a=1", want: "https://example.com/?b=2&a=1"},
+		{name: "query sorted", raw: "https://example.com/?b=2&a=1&b=1", sortQuery: true, want: "https://example.com/?a=1&b=2&b=1"},
+		{name: "javascript", raw: "javascript:alert(1)", reason: ReasonSchemeNotAllowed},
+		{name: "ftp", raw: "ftp://example.com/file", reason: ReasonSchemeNotAllowed},
+		{name: "relative", raw: "/some/path", reason: ReasonRelative},
+		{name: "garbage", raw: "not a url", reason: ReasonRelative},
+		{name: "malformed", raw: "http://exa mple.com/%zz", reason: ReasonMalformed},
+		{name: "no host", raw: "https:///path", reason: ReasonMissingHost},
+		{name: "opaque", raw: "http:example.com", reason: ReasonMissingHost},
+		{name: "bad host", raw: "https://exa_mple..com/", reason: ReasonInvalidHost},
+		{name: "bad port", raw: "https://example.com:70000/", reason: ReasonInvalidPort},
+		{name: "too long", raw: "https://example.com/" + strings.Repeat("a", urlMaxLength), reason: ReasonTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeURL(tt.raw, URLPolicy{SortQuery: tt.sortQuery})
+			if tt.reason == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+				return
+			}
+			var urlErr *URLError
+			if assert.ErrorAs(t, err, &urlErr) {
+				assert.Equal(t, tt.reason, urlErr.Reason)
+				assert.True(t, errors.Is(err, ErrInvalidURL))
+			}
+		})
+	}
+
+	// Пустой URL и схема не из списка разрешённых
+	_, err := NormalizeURL("  ", URLPolicy{})
+	assert.ErrorIs(t, err, ErrEmptyURL)
+	_, err = NormalizeURL("http://example.com", URLPolicy{Schemes: []string{"https"}})
+	assert.ErrorIs(t, err, ErrInvalidURL)
+}
@@ -0,0 +1,16 @@
+package utils
+
+import "net"
+
+// AnonymizeIP zeroes the host part of an address: the last octet for IPv4
+// and everything past the /48 prefix for IPv6.
+func AnonymizeIP(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String()
+	}
+	return parsed.Mask(net.CIDRMask(48, 128)).String()
+}
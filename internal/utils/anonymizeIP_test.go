@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAnonymizeIP(t *testing.T) {
+	tests := []struct {
+		name string
+		ip   string
+		want string
+	}{
+		{name: "IPv4: обнуляется последний октет", ip: "203.0.113.77", want: "203.0.113.0"},
+		{name: "IPv4 уже без хоста", ip: "10.0.0.0", want: "10.0.0.0"},
+		{name: "IPv4 в IPv6-записи", ip: "::ffff:198.51.100.9", want: "198.51.100.0"},
+		{name: "IPv6: остаётся префикс /48", ip: "2001:db8:abcd:12:3456:789a:bcde:f012", want: "2001:db8:abcd::"},
+		{name: "IPv6 loopback", ip: "::1", want: "::"},
+		{name: "не адрес", ip: "not-an-ip", want: ""},
+		{name: "пустая строка", ip: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, AnonymizeIP(tt.ip))
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	Base26Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	// Base58Alphabet leaves out 0, O, I and l, which are easy to mistake for each other.
+	Base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	Base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+var ErrInvalidCode = errors.New("invalid short code")
+
+// CodeGenerator turns link IDs into short codes and back.
+type CodeGenerator interface {
+	Encode(id int) string
+	Decode(code string) (int, error)
+}
+
+// AlphabetGenerator writes IDs in bijective base-N over its alphabet, so
+// every positive ID maps to exactly one code and there are no leading-zero
+// duplicates. With Base26Alphabet it produces the original A, B, ..., Z, AA codes.
+type AlphabetGenerator struct {
+	alphabet string
+	index    map[byte]int
+}
+
+func NewAlphabetGenerator(alphabet string) (*AlphabetGenerator, error) {
+	if len(alphabet) < 2 {
+		return nil, fmt.Errorf("alphabet must contain at least 2 characters")
+	}
+
+	index := make(map[byte]int, len(alphabet))
+	for i := 0; i < len(alphabet); i++ {
+		c := alphabet[i]
+		if c <= ' ' || c > '~' || c == '/' || c == '?' || c == '#' || c == '%' {
+			return nil, fmt.Errorf("alphabet character %q is not allowed in a URL path", c)
+		}
+		if _, exists := index[c]; exists {
+			return nil, fmt.Errorf("alphabet character %q is repeated", c)
+		}
+		index[c] = i
+	}
+
+	return &AlphabetGenerator{alphabet: alphabet, index: index}, nil
+}
+
+// NewCodeGenerator builds a generator by name: base26, base58, base62, or
+// custom with the given alphabet.
+func NewCodeGenerator(name string, alphabet string) (CodeGenerator, error) {
+	switch name {
+	case "base26":
+		return NewAlphabetGenerator(Base26Alphabet)
+	case "base58":
+		return NewAlphabetGenerator(Base58Alphabet)
+	case "", "base62":
+		return NewAlphabetGenerator(Base62Alphabet)
+	case "custom":
+		return NewAlphabetGenerator(alphabet)
+	}
+	return nil, fmt.Errorf("unknown code generator %q", name)
+}
+
+func (g *AlphabetGenerator) Encode(id int) string {
+	base := len(g.alphabet)
+	var result []byte
+	for id > 0 {
+		id--
+		result = append(result, g.alphabet[id%base])
+		id /= base
+	}
+
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return string(result)
+}
+
+func (g *AlphabetGenerator) Decode(code string) (int, error) {
+	if code == "" {
+		return 0, ErrInvalidCode
+	}
+
+	base := len(g.alphabet)
+	var id int
+	for i := 0; i < len(code); i++ {
+		digit, ok := g.index[code[i]]
+		if !ok {
+			return 0, ErrInvalidCode
+		}
+		if id > (math.MaxInt-digit-1)/base {
+			return 0, ErrInvalidCode
+		}
+		id = id*base + digit + 1
+	}
+	return id, nil
+}
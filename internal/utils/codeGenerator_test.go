@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestAlphabetGeneratorBase26(t *testing.T) {
+	gen, err := NewCodeGenerator("base26", "")
+	require.NoError(t, err)
+
+	// Совпадает с прежней схемой A..Z, AA..
+	assert.Equal(t, "A", gen.Encode(1))
+	assert.Equal(t, "Z", gen.Encode(26))
+	assert.Equal(t, "AA", gen.Encode(27))
+	assert.Equal(t, "AZ", gen.Encode(52))
+	assert.Equal(t, "BA", gen.Encode(53))
+}
+
+func TestAlphabetGeneratorRoundTrip(t *testing.T) {
+	for _, name := range []string{"base26", "base58", "base62"} {
+		gen, err := NewCodeGenerator(name, "")
+		require.NoError(t, err)
+
+		seen := make(map[string]int)
+		for id := 1; id <= 100000; id++ {
+			code := gen.Encode(id)
+			prev, dup := seen[code]
+			require.False(t, dup, "%s: ids %d and %d share code %q", name, prev, id, code)
+			seen[code] = id
+
+			decoded, err := gen.Decode(code)
+			require.NoError(t, err)
+			require.Equal(t, id, decoded)
+		}
+	}
+}
+
+func TestAlphabetGeneratorShorterCodes(t *testing.T) {
+	base26, _ := NewCodeGenerator("base26", "")
+	base62, _ := NewCodeGenerator("base62", "")
+
+	assert.Len(t, base26.Encode(1000000), 5)
+	assert.Len(t, base62.Encode(1000000), 4)
+}
+
+func TestAlphabetGeneratorDecodeInvalid(t *testing.T) {
+	gen, _ := NewCodeGenerator("base58", "")
+
+	_, err := gen.Decode("")
+	assert.ErrorIs(t, err, ErrInvalidCode)
+
+	// 0 и O исключены из base58
+	_, err = gen.Decode("0O")
+	assert.ErrorIs(t, err, ErrInvalidCode)
+}
+
+func TestNewCodeGeneratorCustom(t *testing.T) {
+	gen, err := NewCodeGenerator("custom", "abc")
+	require.NoError(t, err)
+	assert.Equal(t, "ca", gen.Encode(10))
+
+	_, err = NewCodeGenerator("custom", "aa")
+	assert.Error(t, err)
+
+	_, err = NewCodeGenerator("custom", "a/b")
+	assert.Error(t, err)
+
+	_, err = NewCodeGenerator("base100", "")
+	assert.Error(t, err)
+}
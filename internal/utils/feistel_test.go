@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestFeistelGeneratorBijective(t *testing.T) {
+	base62, _ := NewCodeGenerator("base62", "")
+	gen, err := NewFeistelGenerator(base62, "secret", 16)
+	require.NoError(t, err)
+
+	// На домене 2^16 перестановка должна быть взаимно однозначной
+	seen := make(map[string]int, 1<<16)
+	for id := 1; id <= 1<<16; id++ {
+		code := gen.Encode(id)
+		prev, dup := seen[code]
+		require.False(t, dup, "ids %d and %d share code %q", prev, id, code)
+		seen[code] = id
+
+		decoded, err := gen.Decode(code)
+		require.NoError(t, err)
+		require.Equal(t, id, decoded)
+	}
+}
+
+func TestFeistelGeneratorHidesSequence(t *testing.T) {
+	base62, _ := NewCodeGenerator("base62", "")
+	gen, err := NewFeistelGenerator(base62, "secret", 32)
+	require.NoError(t, err)
+	other, err := NewFeistelGenerator(base62, "another secret", 32)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, base62.Encode(1), gen.Encode(1))
+	assert.NotEqual(t, gen.Encode(1), other.Encode(1))
+	// Коды остаются короткими: 2^32 укладывается в 6 символов base62
+	assert.LessOrEqual(t, len(gen.Encode(1)), 6)
+}
+
+func TestNewFeistelGeneratorInvalid(t *testing.T) {
+	base62, _ := NewCodeGenerator("base62", "")
+
+	_, err := NewFeistelGenerator(base62, "", 32)
+	assert.Error(t, err)
+
+	_, err = NewFeistelGenerator(base62, "secret", 31)
+	assert.Error(t, err)
+}
@@ -0,0 +1,182 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: keys.go
+//
+// Generated by this command:
+//
+//	mockgen -source=keys.go -destination=../../mocks/keys_mock.go
+//
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+	model "urlShortener/internal/model"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockKeyRepository is a mock of KeyRepository interface.
+type MockKeyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockKeyRepositoryMockRecorder
+}
+
+// MockKeyRepositoryMockRecorder is the mock recorder for MockKeyRepository.
+type MockKeyRepositoryMockRecorder struct {
+	mock *MockKeyRepository
+}
+
+// NewMockKeyRepository creates a new mock instance.
+func NewMockKeyRepository(ctrl *gomock.Controller) *MockKeyRepository {
+	mock := &MockKeyRepository{ctrl: ctrl}
+	mock.recorder = &MockKeyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKeyRepository) EXPECT() *MockKeyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateKey mocks base method.
+func (m *MockKeyRepository) CreateKey(ctx context.Context, key *model.APIKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateKey", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateKey indicates an expected call of CreateKey.
+func (mr *MockKeyRepositoryMockRecorder) CreateKey(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateKey", reflect.TypeOf((*MockKeyRepository)(nil).CreateKey), ctx, key)
+}
+
+// GetKeyByHash mocks base method.
+func (m *MockKeyRepository) GetKeyByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKeyByHash", ctx, hash)
+	ret0, _ := ret[0].(*model.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKeyByHash indicates an expected call of GetKeyByHash.
+func (mr *MockKeyRepositoryMockRecorder) GetKeyByHash(ctx, hash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeyByHash", reflect.TypeOf((*MockKeyRepository)(nil).GetKeyByHash), ctx, hash)
+}
+
+// ListKeys mocks base method.
+func (m *MockKeyRepository) ListKeys(ctx context.Context) ([]model.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListKeys", ctx)
+	ret0, _ := ret[0].([]model.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListKeys indicates an expected call of ListKeys.
+func (mr *MockKeyRepositoryMockRecorder) ListKeys(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListKeys", reflect.TypeOf((*MockKeyRepository)(nil).ListKeys), ctx)
+}
+
+// RevokeKey mocks base method.
+func (m *MockKeyRepository) RevokeKey(ctx context.Context, id int, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeKey", ctx, id, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeKey indicates an expected call of RevokeKey.
+func (mr *MockKeyRepositoryMockRecorder) RevokeKey(ctx, id, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeKey", reflect.TypeOf((*MockKeyRepository)(nil).RevokeKey), ctx, id, now)
+}
+
+// MockKeyServiceInterface is a mock of KeyServiceInterface interface.
+type MockKeyServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockKeyServiceInterfaceMockRecorder
+}
+
+// MockKeyServiceInterfaceMockRecorder is the mock recorder for MockKeyServiceInterface.
+type MockKeyServiceInterfaceMockRecorder struct {
+	mock *MockKeyServiceInterface
+}
+
+// NewMockKeyServiceInterface creates a new mock instance.
+func NewMockKeyServiceInterface(ctrl *gomock.Controller) *MockKeyServiceInterface {
+	mock := &MockKeyServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockKeyServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKeyServiceInterface) EXPECT() *MockKeyServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Authenticate mocks base method.
+func (m *MockKeyServiceInterface) Authenticate(ctx context.Context, rawKey string) (*model.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, rawKey)
+	ret0, _ := ret[0].(*model.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockKeyServiceInterfaceMockRecorder) Authenticate(ctx, rawKey any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockKeyServiceInterface)(nil).Authenticate), ctx, rawKey)
+}
+
+// CreateKey mocks base method.
+func (m *MockKeyServiceInterface) CreateKey(ctx context.Context, name string) (*model.KeyResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateKey", ctx, name)
+	ret0, _ := ret[0].(*model.KeyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateKey indicates an expected call of CreateKey.
+func (mr *MockKeyServiceInterfaceMockRecorder) CreateKey(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateKey", reflect.TypeOf((*MockKeyServiceInterface)(nil).CreateKey), ctx, name)
+}
+
+// ListKeys mocks base method.
+func (m *MockKeyServiceInterface) ListKeys(ctx context.Context) ([]model.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListKeys", ctx)
+	ret0, _ := ret[0].([]model.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListKeys indicates an expected call of ListKeys.
+func (mr *MockKeyServiceInterfaceMockRecorder) ListKeys(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListKeys", reflect.TypeOf((*MockKeyServiceInterface)(nil).ListKeys), ctx)
+}
+
+// RevokeKey mocks base method.
+func (m *MockKeyServiceInterface) RevokeKey(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeKey", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeKey indicates an expected call of RevokeKey.
+func (mr *MockKeyServiceInterfaceMockRecorder) RevokeKey(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeKey", reflect.TypeOf((*MockKeyServiceInterface)(nil).RevokeKey), ctx, id)
+}
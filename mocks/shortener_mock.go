@@ -12,7 +12,9 @@ package mock_service
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 	model "urlShortener/internal/model"
+	service "urlShortener/internal/service"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -41,32 +43,76 @@ func (m *MockSwapRepository) EXPECT() *MockSwapRepositoryMockRecorder {
 }
 
 // CheckDublicate mocks base method.
-func (m *MockSwapRepository) CheckDublicate(ctx context.Context, originalURL string) (string, error) {
+func (m *MockSwapRepository) CheckDublicate(ctx context.Context, originalURL, owner string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CheckDublicate", ctx, originalURL)
+	ret := m.ctrl.Call(m, "CheckDublicate", ctx, originalURL, owner)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CheckDublicate indicates an expected call of CheckDublicate.
-func (mr *MockSwapRepositoryMockRecorder) CheckDublicate(ctx, originalURL any) *gomock.Call {
+func (mr *MockSwapRepositoryMockRecorder) CheckDublicate(ctx, originalURL, owner any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckDublicate", reflect.TypeOf((*MockSwapRepository)(nil).CheckDublicate), ctx, originalURL)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckDublicate", reflect.TypeOf((*MockSwapRepository)(nil).CheckDublicate), ctx, originalURL, owner)
 }
 
 // CreateShortURL mocks base method.
-func (m *MockSwapRepository) CreateShortURL(ctx context.Context, id int, shortURL, originalURL string) error {
+func (m *MockSwapRepository) CreateShortURL(ctx context.Context, link *model.Link) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateShortURL", ctx, id, shortURL, originalURL)
+	ret := m.ctrl.Call(m, "CreateShortURL", ctx, link)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateShortURL indicates an expected call of CreateShortURL.
-func (mr *MockSwapRepositoryMockRecorder) CreateShortURL(ctx, id, shortURL, originalURL any) *gomock.Call {
+func (mr *MockSwapRepositoryMockRecorder) CreateShortURL(ctx, link any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateShortURL", reflect.TypeOf((*MockSwapRepository)(nil).CreateShortURL), ctx, id, shortURL, originalURL)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateShortURL", reflect.TypeOf((*MockSwapRepository)(nil).CreateShortURL), ctx, link)
+}
+
+// CreateShortURLs mocks base method.
+func (m *MockSwapRepository) CreateShortURLs(ctx context.Context, links []*model.Link) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateShortURLs", ctx, links)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateShortURLs indicates an expected call of CreateShortURLs.
+func (mr *MockSwapRepositoryMockRecorder) CreateShortURLs(ctx, links any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateShortURLs", reflect.TypeOf((*MockSwapRepository)(nil).CreateShortURLs), ctx, links)
+}
+
+// DeleteExpired mocks base method.
+func (m *MockSwapRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", ctx, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockSwapRepositoryMockRecorder) DeleteExpired(ctx, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockSwapRepository)(nil).DeleteExpired), ctx, now)
+}
+
+// DeleteLink mocks base method.
+func (m *MockSwapRepository) DeleteLink(ctx context.Context, shortURL string, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLink", ctx, shortURL, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteLink indicates an expected call of DeleteLink.
+func (mr *MockSwapRepositoryMockRecorder) DeleteLink(ctx, shortURL, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLink", reflect.TypeOf((*MockSwapRepository)(nil).DeleteLink), ctx, shortURL, now)
 }
 
 // GetNextID mocks base method.
@@ -84,11 +130,26 @@ func (mr *MockSwapRepositoryMockRecorder) GetNextID(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNextID", reflect.TypeOf((*MockSwapRepository)(nil).GetNextID), ctx)
 }
 
+// GetNextIDs mocks base method.
+func (m *MockSwapRepository) GetNextIDs(ctx context.Context, n int) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNextIDs", ctx, n)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNextIDs indicates an expected call of GetNextIDs.
+func (mr *MockSwapRepositoryMockRecorder) GetNextIDs(ctx, n any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNextIDs", reflect.TypeOf((*MockSwapRepository)(nil).GetNextIDs), ctx, n)
+}
+
 // GetOriginalURL mocks base method.
-func (m *MockSwapRepository) GetOriginalURL(ctx context.Context, shortURL string) (string, error) {
+func (m *MockSwapRepository) GetOriginalURL(ctx context.Context, shortURL string) (*model.Link, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetOriginalURL", ctx, shortURL)
-	ret0, _ := ret[0].(string)
+	ret0, _ := ret[0].(*model.Link)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -99,6 +160,117 @@ func (mr *MockSwapRepositoryMockRecorder) GetOriginalURL(ctx, shortURL any) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOriginalURL", reflect.TypeOf((*MockSwapRepository)(nil).GetOriginalURL), ctx, shortURL)
 }
 
+// GetOriginalURLs mocks base method.
+func (m *MockSwapRepository) GetOriginalURLs(ctx context.Context, shortURLs []string) (map[string]model.Link, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOriginalURLs", ctx, shortURLs)
+	ret0, _ := ret[0].(map[string]model.Link)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOriginalURLs indicates an expected call of GetOriginalURLs.
+func (mr *MockSwapRepositoryMockRecorder) GetOriginalURLs(ctx, shortURLs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOriginalURLs", reflect.TypeOf((*MockSwapRepository)(nil).GetOriginalURLs), ctx, shortURLs)
+}
+
+// ListLinks mocks base method.
+func (m *MockSwapRepository) ListLinks(ctx context.Context, query model.ListQuery) ([]model.Link, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLinks", ctx, query)
+	ret0, _ := ret[0].([]model.Link)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLinks indicates an expected call of ListLinks.
+func (mr *MockSwapRepositoryMockRecorder) ListLinks(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLinks", reflect.TypeOf((*MockSwapRepository)(nil).ListLinks), ctx, query)
+}
+
+// ShortURLExists mocks base method.
+func (m *MockSwapRepository) ShortURLExists(ctx context.Context, shortURL string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ShortURLExists", ctx, shortURL)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ShortURLExists indicates an expected call of ShortURLExists.
+func (mr *MockSwapRepositoryMockRecorder) ShortURLExists(ctx, shortURL any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShortURLExists", reflect.TypeOf((*MockSwapRepository)(nil).ShortURLExists), ctx, shortURL)
+}
+
+// UpdateLink mocks base method.
+func (m *MockSwapRepository) UpdateLink(ctx context.Context, link *model.Link) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLink", ctx, link)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateLink indicates an expected call of UpdateLink.
+func (mr *MockSwapRepositoryMockRecorder) UpdateLink(ctx, link any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLink", reflect.TypeOf((*MockSwapRepository)(nil).UpdateLink), ctx, link)
+}
+
+// MockClickRepository is a mock of ClickRepository interface.
+type MockClickRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockClickRepositoryMockRecorder
+}
+
+// MockClickRepositoryMockRecorder is the mock recorder for MockClickRepository.
+type MockClickRepositoryMockRecorder struct {
+	mock *MockClickRepository
+}
+
+// NewMockClickRepository creates a new mock instance.
+func NewMockClickRepository(ctrl *gomock.Controller) *MockClickRepository {
+	mock := &MockClickRepository{ctrl: ctrl}
+	mock.recorder = &MockClickRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClickRepository) EXPECT() *MockClickRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetStats mocks base method.
+func (m *MockClickRepository) GetStats(ctx context.Context, shortURL string) (*model.Stats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStats", ctx, shortURL)
+	ret0, _ := ret[0].(*model.Stats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStats indicates an expected call of GetStats.
+func (mr *MockClickRepositoryMockRecorder) GetStats(ctx, shortURL any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStats", reflect.TypeOf((*MockClickRepository)(nil).GetStats), ctx, shortURL)
+}
+
+// SaveClicks mocks base method.
+func (m *MockClickRepository) SaveClicks(ctx context.Context, clicks []model.Click) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveClicks", ctx, clicks)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveClicks indicates an expected call of SaveClicks.
+func (mr *MockClickRepositoryMockRecorder) SaveClicks(ctx, clicks any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveClicks", reflect.TypeOf((*MockClickRepository)(nil).SaveClicks), ctx, clicks)
+}
+
 // MockShortenerServiceInterface is a mock of ShortenerServiceInterface interface.
 type MockShortenerServiceInterface struct {
 	ctrl     *gomock.Controller
@@ -123,25 +295,69 @@ func (m *MockShortenerServiceInterface) EXPECT() *MockShortenerServiceInterfaceM
 }
 
 // CreateShortURL mocks base method.
-func (m *MockShortenerServiceInterface) CreateShortURL(ctx context.Context, url string) (*model.Response, error) {
+func (m *MockShortenerServiceInterface) CreateShortURL(ctx context.Context, req model.Request) (*model.Response, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateShortURL", ctx, url)
+	ret := m.ctrl.Call(m, "CreateShortURL", ctx, req)
 	ret0, _ := ret[0].(*model.Response)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateShortURL indicates an expected call of CreateShortURL.
-func (mr *MockShortenerServiceInterfaceMockRecorder) CreateShortURL(ctx, url any) *gomock.Call {
+func (mr *MockShortenerServiceInterfaceMockRecorder) CreateShortURL(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateShortURL", reflect.TypeOf((*MockShortenerServiceInterface)(nil).CreateShortURL), ctx, req)
+}
+
+// CreateShortURLs mocks base method.
+func (m *MockShortenerServiceInterface) CreateShortURLs(ctx context.Context, reqs []model.Request) ([]service.BatchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateShortURLs", ctx, reqs)
+	ret0, _ := ret[0].([]service.BatchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateShortURLs indicates an expected call of CreateShortURLs.
+func (mr *MockShortenerServiceInterfaceMockRecorder) CreateShortURLs(ctx, reqs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateShortURLs", reflect.TypeOf((*MockShortenerServiceInterface)(nil).CreateShortURLs), ctx, reqs)
+}
+
+// DeleteLink mocks base method.
+func (m *MockShortenerServiceInterface) DeleteLink(ctx context.Context, url, owner string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLink", ctx, url, owner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteLink indicates an expected call of DeleteLink.
+func (mr *MockShortenerServiceInterfaceMockRecorder) DeleteLink(ctx, url, owner any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLink", reflect.TypeOf((*MockShortenerServiceInterface)(nil).DeleteLink), ctx, url, owner)
+}
+
+// ExpandURLs mocks base method.
+func (m *MockShortenerServiceInterface) ExpandURLs(ctx context.Context, codes []string) (map[string]model.ExpandResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpandURLs", ctx, codes)
+	ret0, _ := ret[0].(map[string]model.ExpandResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpandURLs indicates an expected call of ExpandURLs.
+func (mr *MockShortenerServiceInterfaceMockRecorder) ExpandURLs(ctx, codes any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateShortURL", reflect.TypeOf((*MockShortenerServiceInterface)(nil).CreateShortURL), ctx, url)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpandURLs", reflect.TypeOf((*MockShortenerServiceInterface)(nil).ExpandURLs), ctx, codes)
 }
 
 // GetOriginalURL mocks base method.
-func (m *MockShortenerServiceInterface) GetOriginalURL(ctx context.Context, url string) (*model.Response, error) {
+func (m *MockShortenerServiceInterface) GetOriginalURL(ctx context.Context, url string) (*model.Link, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetOriginalURL", ctx, url)
-	ret0, _ := ret[0].(*model.Response)
+	ret0, _ := ret[0].(*model.Link)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -151,3 +367,60 @@ func (mr *MockShortenerServiceInterfaceMockRecorder) GetOriginalURL(ctx, url any
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOriginalURL", reflect.TypeOf((*MockShortenerServiceInterface)(nil).GetOriginalURL), ctx, url)
 }
+
+// GetStats mocks base method.
+func (m *MockShortenerServiceInterface) GetStats(ctx context.Context, url, owner string) (*model.Stats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStats", ctx, url, owner)
+	ret0, _ := ret[0].(*model.Stats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStats indicates an expected call of GetStats.
+func (mr *MockShortenerServiceInterfaceMockRecorder) GetStats(ctx, url, owner any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStats", reflect.TypeOf((*MockShortenerServiceInterface)(nil).GetStats), ctx, url, owner)
+}
+
+// ListLinks mocks base method.
+func (m *MockShortenerServiceInterface) ListLinks(ctx context.Context, req model.ListRequest) (*model.LinkPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLinks", ctx, req)
+	ret0, _ := ret[0].(*model.LinkPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLinks indicates an expected call of ListLinks.
+func (mr *MockShortenerServiceInterfaceMockRecorder) ListLinks(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLinks", reflect.TypeOf((*MockShortenerServiceInterface)(nil).ListLinks), ctx, req)
+}
+
+// RecordClick mocks base method.
+func (m *MockShortenerServiceInterface) RecordClick(click model.Click) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RecordClick", click)
+}
+
+// RecordClick indicates an expected call of RecordClick.
+func (mr *MockShortenerServiceInterfaceMockRecorder) RecordClick(click any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordClick", reflect.TypeOf((*MockShortenerServiceInterface)(nil).RecordClick), click)
+}
+
+// UpdateLink mocks base method.
+func (m *MockShortenerServiceInterface) UpdateLink(ctx context.Context, url string, req model.UpdateRequest) (*model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLink", ctx, url, req)
+	ret0, _ := ret[0].(*model.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateLink indicates an expected call of UpdateLink.
+func (mr *MockShortenerServiceInterfaceMockRecorder) UpdateLink(ctx, url, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLink", reflect.TypeOf((*MockShortenerServiceInterface)(nil).UpdateLink), ctx, url, req)
+}
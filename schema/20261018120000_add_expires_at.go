@@ -0,0 +1,21 @@
+package schema
+
+import "github.com/pressly/goose/v3"
+
+// SQLite drivers only parse TIMESTAMP columns back into times, TIMESTAMPTZ
+// would come back as text.
+func init() {
+	goose.AddMigrationContext(
+		byDialect(`
+ALTER TABLE links ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS links_expires_at_idx ON links (expires_at) WHERE expires_at IS NOT NULL;
+`, `
+ALTER TABLE links ADD COLUMN expires_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS links_expires_at_idx ON links (expires_at) WHERE expires_at IS NOT NULL;
+`),
+		both(`
+DROP INDEX IF EXISTS links_expires_at_idx;
+ALTER TABLE links DROP COLUMN expires_at;
+`),
+	)
+}
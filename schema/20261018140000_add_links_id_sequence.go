@@ -0,0 +1,25 @@
+package schema
+
+import "github.com/pressly/goose/v3"
+
+// SQLite has no sequences, a one-row counter table stands in for links_id_seq.
+func init() {
+	goose.AddMigrationContext(
+		byDialect(`
+CREATE SEQUENCE IF NOT EXISTS links_id_seq OWNED BY links.id;
+SELECT setval('links_id_seq', COALESCE(MAX(id), 0) + 1, false) FROM links;
+ALTER TABLE links ALTER COLUMN id SET DEFAULT nextval('links_id_seq');
+`, `
+CREATE TABLE IF NOT EXISTS links_id_seq (
+     last_value INTEGER NOT NULL
+);
+INSERT INTO links_id_seq (last_value) SELECT COALESCE(MAX(id), 0) FROM links;
+`),
+		byDialect(`
+ALTER TABLE links ALTER COLUMN id DROP DEFAULT;
+DROP SEQUENCE IF EXISTS links_id_seq;
+`, `
+DROP TABLE IF EXISTS links_id_seq;
+`),
+	)
+}
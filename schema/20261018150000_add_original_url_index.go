@@ -0,0 +1,17 @@
+package schema
+
+import "github.com/pressly/goose/v3"
+
+// SQLite only has B-tree indexes.
+func init() {
+	goose.AddMigrationContext(
+		byDialect(`
+CREATE INDEX IF NOT EXISTS links_original_url_idx ON links USING HASH (original_url) WHERE expires_at IS NULL;
+`, `
+CREATE INDEX IF NOT EXISTS links_original_url_idx ON links (original_url) WHERE expires_at IS NULL;
+`),
+		both(`
+DROP INDEX IF EXISTS links_original_url_idx;
+`),
+	)
+}
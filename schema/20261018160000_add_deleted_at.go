@@ -0,0 +1,17 @@
+package schema
+
+import "github.com/pressly/goose/v3"
+
+// Same column types as expires_at.
+func init() {
+	goose.AddMigrationContext(
+		byDialect(`
+ALTER TABLE links ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+`, `
+ALTER TABLE links ADD COLUMN deleted_at TIMESTAMP;
+`),
+		both(`
+ALTER TABLE links DROP COLUMN deleted_at;
+`),
+	)
+}
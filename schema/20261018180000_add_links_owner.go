@@ -0,0 +1,28 @@
+package schema
+
+import "github.com/pressly/goose/v3"
+
+// SQLite cannot add a column with a non-constant default, existing rows get
+// the migration time by an UPDATE instead. Every insert sets created_at.
+func init() {
+	goose.AddMigrationContext(
+		byDialect(`
+ALTER TABLE links ADD COLUMN IF NOT EXISTS owner VARCHAR(256) NOT NULL DEFAULT '';
+ALTER TABLE links ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+CREATE INDEX IF NOT EXISTS links_owner_created_at_idx ON links (owner, created_at, id);
+CREATE INDEX IF NOT EXISTS links_created_at_idx ON links (created_at, id);
+`, `
+ALTER TABLE links ADD COLUMN owner VARCHAR(256) NOT NULL DEFAULT '';
+ALTER TABLE links ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00+00:00';
+UPDATE links SET created_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now');
+CREATE INDEX IF NOT EXISTS links_owner_created_at_idx ON links (owner, created_at, id);
+CREATE INDEX IF NOT EXISTS links_created_at_idx ON links (created_at, id);
+`),
+		both(`
+DROP INDEX IF EXISTS links_created_at_idx;
+DROP INDEX IF EXISTS links_owner_created_at_idx;
+ALTER TABLE links DROP COLUMN created_at;
+ALTER TABLE links DROP COLUMN owner;
+`),
+	)
+}
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Links are owned by key name, so two live keys must never share one.
+CREATE UNIQUE INDEX IF NOT EXISTS api_keys_name_idx ON api_keys (name) WHERE revoked_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS api_keys_name_idx;
+-- +goose StatementEnd
@@ -0,0 +1,42 @@
+// Package schema holds the goose migrations of the links database. One
+// chain serves both Postgres and SQLite: most steps are SQL files both
+// accept, the few statements they disagree on live in Go migrations that
+// run the variant of the dialect being migrated.
+package schema
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+const (
+	DialectPostgres = "pgx"
+	DialectSQLite   = "sqlite3"
+)
+
+var dialect = DialectPostgres
+
+// SetDialect tells the Go migrations which database goose is migrating,
+// goose itself does not expose it.
+func SetDialect(d string) {
+	dialect = d
+}
+
+// byDialect runs postgres or sqlite, whichever matches the current dialect.
+func byDialect(postgres, sqlite string) goose.GoMigrationContext {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		query := postgres
+		if dialect == DialectSQLite {
+			query = sqlite
+		}
+		_, err := tx.ExecContext(ctx, query)
+		return err
+	}
+}
+
+// both runs a query the dialects agree on.
+func both(query string) goose.GoMigrationContext {
+	return byDialect(query, query)
+}